@@ -96,16 +96,34 @@ func Register(a aggregate) {
 
 // Save events to the event store
 func saveEvents(eventStore core.EventStore, events []eventsourcing.Event) (eventsourcing.Version, error) {
+	esEvents, err := toCoreEvents(events)
+	if err != nil {
+		return 0, err
+	}
+
+	err = eventStore.Save(esEvents)
+	if err != nil {
+		if errors.Is(err, core.ErrConcurrency) {
+			return 0, eventsourcing.ErrConcurrency
+		}
+		return 0, fmt.Errorf("error from event store: %w", err)
+	}
+
+	return eventsourcing.Version(esEvents[len(esEvents)-1].GlobalVersion), nil
+}
+
+// toCoreEvents serializes the events to be saved in the event store
+func toCoreEvents(events []eventsourcing.Event) ([]core.Event, error) {
 	var esEvents = make([]core.Event, 0, len(events))
 
 	for _, event := range events {
 		data, err := internal.EventEncoder.Serialize(event.Data())
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 		metadata, err := internal.EventEncoder.Serialize(event.Metadata())
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 
 		esEvent := core.Event{
@@ -119,20 +137,11 @@ func saveEvents(eventStore core.EventStore, events []eventsourcing.Event) (event
 		}
 		_, ok := internal.GlobalRegister.EventRegistered(esEvent)
 		if !ok {
-			return 0, fmt.Errorf("%s %w", esEvent.Reason, eventsourcing.ErrEventNotRegistered)
+			return nil, fmt.Errorf("%s %w", esEvent.Reason, eventsourcing.ErrEventNotRegistered)
 		}
 		esEvents = append(esEvents, esEvent)
 	}
-
-	err := eventStore.Save(esEvents)
-	if err != nil {
-		if errors.Is(err, core.ErrConcurrency) {
-			return 0, eventsourcing.ErrConcurrency
-		}
-		return 0, fmt.Errorf("error from event store: %w", err)
-	}
-
-	return eventsourcing.Version(esEvents[len(esEvents)-1].GlobalVersion), nil
+	return esEvents, nil
 }
 
 // getEvents return event iterator based on aggregate inputs from the event store
@@ -0,0 +1,80 @@
+package aggregate
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+// cache keeps the serialized state of the most recently used aggregates, each caller builds its own aggregate
+// instance from the state
+type cache struct {
+	lock    sync.Mutex
+	size    int
+	entries map[cacheKey]*list.Element
+	order   *list.List // most recently used first
+}
+
+// entry is the state of an aggregate in the cache
+type entry struct {
+	key      cacheKey
+	snapshot core.Snapshot
+}
+
+func newCache(size int) *cache {
+	return &cache{
+		size:    size,
+		entries: make(map[cacheKey]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// cacheKey is the aggregate type and id of a cached aggregate
+type cacheKey struct {
+	aggregateType string
+	id            string
+}
+
+// get returns the state of the aggregate and marks it as used
+func (c *cache) get(key cacheKey) (core.Snapshot, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return core.Snapshot{}, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*entry).snapshot, true
+}
+
+// put stores the state of the aggregate unless a later version is cached, the least recently used aggregate
+// is evicted when the cache is full
+func (c *cache) put(key cacheKey, snapshot core.Snapshot) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if e, ok := c.entries[key]; ok {
+		cached := e.Value.(*entry)
+		if snapshot.Version >= cached.snapshot.Version {
+			cached.snapshot = snapshot
+		}
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&entry{key: key, snapshot: snapshot})
+	if c.order.Len() > c.size {
+		last := c.order.Back()
+		c.order.Remove(last)
+		delete(c.entries, last.Value.(*entry).key)
+	}
+}
+
+// remove evicts the aggregate
+func (c *cache) remove(key cacheKey) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.order.Remove(e)
+		delete(c.entries, key)
+	}
+}
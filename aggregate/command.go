@@ -0,0 +1,136 @@
+package aggregate
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/r23vme/eventsourcing"
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/internal"
+)
+
+// RetryPolicy decides how many times a command is retried when the aggregate is saved with a concurrency error.
+// The zero value does not retry.
+type RetryPolicy struct {
+	Retries    int           // Retries is the number of times the command is retried on the reloaded aggregate
+	Backoff    time.Duration // Backoff is the wait before the first retry, it's doubled for each retry
+	MaxBackoff time.Duration // MaxBackoff caps the wait between retries, 0 has no limit
+}
+
+// DefaultRetryPolicy is the retry policy used by Update
+var DefaultRetryPolicy = RetryPolicy{
+	Retries:    3,
+	Backoff:    time.Millisecond * 10,
+	MaxBackoff: time.Second,
+}
+
+// wait blocks for the backoff before the retry attempt or until the context is done
+func (r RetryPolicy) wait(ctx context.Context, attempt int) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(internal.Backoff(r.Backoff, r.MaxBackoff, attempt)):
+		return nil
+	}
+}
+
+// Commands runs commands on aggregates, loading the aggregate before and saving it after the command
+type Commands struct {
+	EventStore core.EventStore
+	// SnapshotStore is optional, if set aggregates with snapshot methods are loaded from their snapshot and the
+	// events after it
+	SnapshotStore core.SnapshotStore
+	// Retry is the policy applied when the aggregate is saved with a concurrency error
+	Retry RetryPolicy
+}
+
+// Update loads the aggregate with the id from the event store, runs the command on it and saves the aggregate with
+// the default retry policy. See Commands.Update.
+func Update(ctx context.Context, es core.EventStore, id string, a aggregate, command func() error) error {
+	return Commands{EventStore: es, Retry: DefaultRetryPolicy}.Update(ctx, id, a, command)
+}
+
+// Create runs the command creating the aggregate and saves it. See Commands.Create.
+func Create(ctx context.Context, es core.EventStore, a aggregate, command func() error) error {
+	return Commands{EventStore: es}.Create(ctx, a, command)
+}
+
+// Update loads the aggregate with the id, runs the command on it and saves the aggregate. If another process has
+// saved events on the aggregate since it was loaded the aggregate is reset, loaded and the command run again
+// according to the retry policy. The command is not run if the aggregate is not found and the aggregate is not
+// saved if the command returns an error.
+func (c Commands) Update(ctx context.Context, id string, a aggregate, command func() error) error {
+	return update(ctx, c.Retry, id, a, command, c.load, c.save)
+}
+
+// Create runs the command creating the aggregate and saves it. The aggregate is reset before the command is run.
+// ErrConcurrency is returned if an aggregate with the same id already exists.
+func (c Commands) Create(ctx context.Context, a aggregate, command func() error) error {
+	return create(ctx, a, command, c.save)
+}
+
+// update loads the aggregate, runs the command and saves the aggregate, and retries on concurrency errors
+func update(ctx context.Context, retry RetryPolicy, id string, a aggregate, command func() error, load func(ctx context.Context, id string, a aggregate) error, save func(a aggregate) error) error {
+	if reflect.ValueOf(a).Kind() != reflect.Ptr {
+		return eventsourcing.ErrAggregateNeedsToBeAPointer
+	}
+	for attempt := 0; ; attempt++ {
+		reset(a)
+		err := load(ctx, id, a)
+		if err != nil {
+			return err
+		}
+		err = command()
+		if err != nil {
+			return err
+		}
+		err = save(a)
+		if !errors.Is(err, eventsourcing.ErrConcurrency) || attempt >= retry.Retries {
+			return err
+		}
+		err = retry.wait(ctx, attempt)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// create runs the command on the reset aggregate and saves it
+func create(ctx context.Context, a aggregate, command func() error, save func(a aggregate) error) error {
+	if reflect.ValueOf(a).Kind() != reflect.Ptr {
+		return eventsourcing.ErrAggregateNeedsToBeAPointer
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	reset(a)
+	err := command()
+	if err != nil {
+		return err
+	}
+	return save(a)
+}
+
+// load builds the aggregate from its snapshot, if the snapshot store is set, and the events after it
+func (c Commands) load(ctx context.Context, id string, a aggregate) error {
+	if s, ok := a.(aggregateSnapshot); ok && c.SnapshotStore != nil {
+		err := getSnapshot(ctx, c.SnapshotStore, id, s)
+		if err != nil && !errors.Is(err, core.ErrSnapshotNotFound) {
+			return err
+		}
+	}
+	return Load(ctx, c.EventStore, id, a)
+}
+
+// save stores the aggregate events in the event store
+func (c Commands) save(a aggregate) error {
+	return Save(c.EventStore, a)
+}
+
+// reset sets the aggregate to its zero value
+func reset(a aggregate) {
+	v := reflect.ValueOf(a).Elem()
+	v.Set(reflect.Zero(v.Type()))
+}
@@ -0,0 +1,156 @@
+package aggregate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/r23vme/eventsourcing"
+	"github.com/r23vme/eventsourcing/aggregate"
+	"github.com/r23vme/eventsourcing/eventstore/memory"
+	snap "github.com/r23vme/eventsourcing/snapshotstore/memory"
+)
+
+func TestUpdate(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	person := Person{}
+	err := aggregate.Create(context.Background(), es, &person, func() error {
+		err := person.SetID("123")
+		if err != nil {
+			return err
+		}
+		aggregate.TrackChange(&person, &Born{Name: "kalle"})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// another process saves an event on the aggregate the first time the command runs
+	var attempts int
+	err = aggregate.Update(context.Background(), es, "123", &person, func() error {
+		attempts++
+		if attempts == 1 {
+			other := Person{}
+			err := aggregate.Load(context.Background(), es, "123", &other)
+			if err != nil {
+				return err
+			}
+			other.GrowOlder()
+			err = aggregate.Save(es, &other)
+			if err != nil {
+				return err
+			}
+		}
+		person.GrowOlder()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 2 {
+		t.Fatalf("expected the command to run 2 times got %d", attempts)
+	}
+	twin := Person{}
+	err = aggregate.Load(context.Background(), es, "123", &twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if twin.Age != 2 || twin.Version() != 3 {
+		t.Fatalf("expected age 2 in version 3 got age %d in version %d", twin.Age, twin.Version())
+	}
+
+	// the zero retry policy returns the concurrency error
+	c := aggregate.Commands{EventStore: es}
+	err = c.Update(context.Background(), "123", &person, func() error {
+		other := Person{}
+		err := aggregate.Load(context.Background(), es, "123", &other)
+		if err != nil {
+			return err
+		}
+		other.GrowOlder()
+		err = aggregate.Save(es, &other)
+		if err != nil {
+			return err
+		}
+		person.GrowOlder()
+		return nil
+	})
+	if !errors.Is(err, eventsourcing.ErrConcurrency) {
+		t.Fatalf("expected concurrency error got %v", err)
+	}
+
+	// the command error is returned and the aggregate not saved
+	commandErr := errors.New("command error")
+	err = aggregate.Update(context.Background(), es, "123", &person, func() error {
+		person.GrowOlder()
+		return commandErr
+	})
+	if !errors.Is(err, commandErr) {
+		t.Fatalf("expected command error got %v", err)
+	}
+
+	err = aggregate.Update(context.Background(), es, "none_existing_id", &person, func() error {
+		t.Fatal("the command should not run")
+		return nil
+	})
+	if !errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		t.Fatalf("expected aggregate not found got %v", err)
+	}
+
+	// an aggregate with the same id can't be created again
+	err = aggregate.Create(context.Background(), es, &person, func() error {
+		err := person.SetID("123")
+		if err != nil {
+			return err
+		}
+		aggregate.TrackChange(&person, &Born{Name: "anka"})
+		return nil
+	})
+	if !errors.Is(err, eventsourcing.ErrConcurrency) {
+		t.Fatalf("expected concurrency error got %v", err)
+	}
+}
+
+func TestUpdateFromSnapshot(t *testing.T) {
+	es := memory.Create()
+	ss := snap.Create()
+	aggregate.Register(&Person{})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.SaveSnapshot(ss, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := aggregate.Commands{EventStore: es, SnapshotStore: ss, Retry: aggregate.DefaultRetryPolicy}
+	twin := Person{}
+	err = c.Update(context.Background(), person.ID(), &twin, func() error {
+		if twin.Age != 2 || twin.Version() != 3 {
+			t.Fatalf("expected age 2 in version 3 got age %d in version %d", twin.Age, twin.Version())
+		}
+		twin.GrowOlder()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if twin.Version() != 4 {
+		t.Fatalf("expected version 4 got %d", twin.Version())
+	}
+}
@@ -0,0 +1,75 @@
+package aggregate
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/r23vme/eventsourcing"
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/fetcher"
+)
+
+// Latest keeps the latest state of the aggregates of the registered types in a snapshot store. Each event is
+// applied with the Transition method of the aggregate and the state is stored as a snapshot keyed on the
+// aggregate id and type.
+type Latest struct {
+	snapshots core.SnapshotStore
+	types     map[string]reflect.Type
+}
+
+// NewLatest keeps the latest state of the aggregate types in the snapshot store. The aggregates and their events
+// has to be registered with Register.
+func NewLatest(ss core.SnapshotStore, aggregates ...aggregate) *Latest {
+	types := make(map[string]reflect.Type)
+	for _, a := range aggregates {
+		types[aggregateType(a)] = reflect.TypeOf(a).Elem()
+	}
+	return &Latest{
+		snapshots: ss,
+		types:     types,
+	}
+}
+
+// Callback applies the event on the stored state of its aggregate. Events already applied on the stored state
+// are skipped.
+func (l *Latest) Callback(ctx context.Context, event eventsourcing.Event) error {
+	typ, ok := l.types[event.AggregateType()]
+	if !ok {
+		return nil
+	}
+	a := reflect.New(typ).Interface().(aggregate)
+	err := getSnapshot(ctx, l.snapshots, event.AggregateID(), a)
+	if err != nil && !errors.Is(err, core.ErrSnapshotNotFound) {
+		return err
+	}
+	if event.Version() <= a.root().Version() {
+		return nil
+	}
+	buildFromHistory(a, []eventsourcing.Event{event})
+	return saveSnapshot(l.snapshots, a)
+}
+
+// Projection creates a checkpoint projection that keeps the stored state up to date. Events from other aggregate
+// types are skipped before they are deserialized.
+func (l *Latest) Projection(name string, cs core.CheckpointStore, fetchFrom core.FetcherFrom) *eventsourcing.Projection {
+	types := make([]string, 0, len(l.types))
+	for typ := range l.types {
+		types = append(types, typ)
+	}
+	return eventsourcing.NewCheckpointProjectionContext(name, cs, func(start core.Version) core.Fetcher {
+		return fetcher.AggregateType(fetchFrom(start), types...)
+	}, l.Callback)
+}
+
+// LoadLatest builds the aggregate from its latest state stored in the snapshot store
+func LoadLatest(ctx context.Context, ss core.SnapshotStore, id string, a aggregate) error {
+	if reflect.ValueOf(a).Kind() != reflect.Ptr {
+		return eventsourcing.ErrAggregateNeedsToBeAPointer
+	}
+	err := getSnapshot(ctx, ss, id, a)
+	if errors.Is(err, core.ErrSnapshotNotFound) {
+		return eventsourcing.ErrAggregateNotFound
+	}
+	return err
+}
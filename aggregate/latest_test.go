@@ -0,0 +1,118 @@
+package aggregate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/r23vme/eventsourcing"
+	"github.com/r23vme/eventsourcing/aggregate"
+	csmemory "github.com/r23vme/eventsourcing/checkpointstore/memory"
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/eventstore/memory"
+	snap "github.com/r23vme/eventsourcing/snapshotstore/memory"
+)
+
+// Counter aggregate without snapshot serialization
+type Counter struct {
+	aggregate.Root
+	Count int
+}
+
+// Incremented event
+type Incremented struct{}
+
+func (c *Counter) Register(f aggregate.RegisterFunc) {
+	f(&Incremented{})
+}
+
+func (c *Counter) Transition(event eventsourcing.Event) {
+	switch event.Data().(type) {
+	case *Incremented:
+		c.Count++
+	}
+}
+
+func TestLatest(t *testing.T) {
+	es := memory.Create()
+	ss := snap.Create()
+	aggregate.Register(&Person{})
+	aggregate.Register(&Counter{})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+	person.GrowOlder()
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter := &Counter{}
+	aggregate.TrackChange(counter, &Incremented{})
+	err = aggregate.Save(es, counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	latest := aggregate.NewLatest(ss, &Person{}, &Counter{})
+	p := latest.Projection("latest", csmemory.Create(), func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	})
+	result := p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	twin := Person{}
+	err = aggregate.LoadLatest(context.Background(), ss, person.ID(), &twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if twin.Name != "kalle" || twin.Age != 2 || twin.Version() != 3 {
+		t.Fatalf("expected kalle aged 2 in version 3 got %s aged %d in version %d", twin.Name, twin.Age, twin.Version())
+	}
+
+	// new events are applied incrementally
+	aggregate.TrackChange(counter, &Incremented{})
+	err = aggregate.Save(es, counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result = p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	c := Counter{}
+	err = aggregate.LoadLatest(context.Background(), ss, counter.ID(), &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Count != 2 || c.Version() != 2 || c.GlobalVersion() != 5 || c.ID() != counter.ID() {
+		t.Fatalf("expected count 2 in version 2 global version 5 got %d in version %d global version %d", c.Count, c.Version(), c.GlobalVersion())
+	}
+
+	// events already applied are skipped when the projection is replayed
+	err = p.Reset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result = p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	c = Counter{}
+	err = aggregate.LoadLatest(context.Background(), ss, counter.ID(), &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Count != 2 {
+		t.Fatalf("expected count 2 got %d", c.Count)
+	}
+
+	err = aggregate.LoadLatest(context.Background(), ss, "none_existing_id", &Counter{})
+	if !errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		t.Fatalf("expected aggregate not found got %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package aggregate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/r23vme/eventsourcing"
+	"github.com/r23vme/eventsourcing/core"
+)
+
+// ErrNoSnapshotMethods is returned when a cached repository is created for an aggregate without the snapshot methods
+var ErrNoSnapshotMethods = errors.New("aggregate has no snapshot methods")
+
+// Repository loads and saves aggregates in the event store and the optional snapshot store it's created with,
+// instead of passing the stores to each call.
+//
+// A repository with a cache keeps the state of the most recently used aggregates of the cached types. A cached
+// aggregate is built from the cached state and only the events after its version are fetched from the event
+// store. The state is serialized in the cache with the snapshot methods of the aggregate, each load builds a new
+// instance. The repository is safe for concurrent use.
+type Repository struct {
+	Commands
+	cache  *cache
+	cached map[string]struct{} // the aggregate types kept in the cache
+}
+
+// NewRepository creates a repository on the stores with the default retry policy, the snapshot store can be nil
+func NewRepository(es core.EventStore, ss core.SnapshotStore) *Repository {
+	return &Repository{
+		Commands: Commands{
+			EventStore:    es,
+			SnapshotStore: ss,
+			Retry:         DefaultRetryPolicy,
+		},
+	}
+}
+
+// NewCachedRepository creates a repository that caches the state of the size most recently used aggregates of the
+// aggregate types, other aggregates are loaded from the stores. The aggregates must have the snapshot methods to
+// be copied in and out of the cache, ErrNoSnapshotMethods is returned otherwise.
+func NewCachedRepository(es core.EventStore, ss core.SnapshotStore, size int, aggregates ...aggregate) (*Repository, error) {
+	r := NewRepository(es, ss)
+	if size <= 0 {
+		return r, nil
+	}
+	r.cached = make(map[string]struct{})
+	for _, a := range aggregates {
+		if _, ok := a.(snapshot); !ok {
+			return nil, fmt.Errorf("%s %w", aggregateType(a), ErrNoSnapshotMethods)
+		}
+		r.cached[aggregateType(a)] = struct{}{}
+	}
+	r.cache = newCache(size)
+	return r, nil
+}
+
+// Load builds the aggregate with the id from the cached state, or its snapshot if the snapshot store is set and
+// the aggregate has snapshot methods, and the events after it
+func (r *Repository) Load(ctx context.Context, id string, a aggregate) error {
+	if reflect.ValueOf(a).Kind() != reflect.Ptr {
+		return eventsourcing.ErrAggregateNeedsToBeAPointer
+	}
+	if !r.caches(a) {
+		return r.load(ctx, id, a)
+	}
+	key := cacheKey{aggregateType(a), id}
+	snap, ok := r.cache.get(key)
+	if ok {
+		reset(a)
+		err := restore(snap, a)
+		if err != nil {
+			r.cache.remove(key)
+			return err
+		}
+		err = Load(ctx, r.EventStore, id, a)
+		if err != nil {
+			return err
+		}
+		if a.root().Version() == eventsourcing.Version(snap.Version) {
+			return nil
+		}
+	} else {
+		err := r.load(ctx, id, a)
+		if err != nil {
+			return err
+		}
+	}
+	return r.put(key, a)
+}
+
+// Save stores the aggregate events in the event store and the state of the aggregate in the cache
+func (r *Repository) Save(a aggregate) error {
+	err := Save(r.EventStore, a)
+	if !r.caches(a) {
+		return err
+	}
+	key := cacheKey{aggregateType(a), a.root().ID()}
+	if err != nil {
+		// the cached state could be behind the event store
+		r.cache.remove(key)
+		return err
+	}
+	return r.put(key, a)
+}
+
+// Update loads the aggregate with the id, runs the command on it and saves the aggregate. See Commands.Update.
+func (r *Repository) Update(ctx context.Context, id string, a aggregate, command func() error) error {
+	return update(ctx, r.Retry, id, a, command, r.Load, r.Save)
+}
+
+// Create runs the command creating the aggregate and saves it. See Commands.Create.
+func (r *Repository) Create(ctx context.Context, a aggregate, command func() error) error {
+	return create(ctx, a, command, r.Save)
+}
+
+// caches returns true if the aggregate type is kept in the cache
+func (r *Repository) caches(a aggregate) bool {
+	if r.cache == nil {
+		return false
+	}
+	_, ok := r.cached[aggregateType(a)]
+	return ok
+}
+
+// put stores the state of the aggregate in the cache
+func (r *Repository) put(key cacheKey, a aggregate) error {
+	snap, err := capture(a)
+	if err != nil {
+		return err
+	}
+	r.cache.put(key, snap)
+	return nil
+}
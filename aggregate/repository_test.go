@@ -0,0 +1,203 @@
+package aggregate_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/r23vme/eventsourcing/aggregate"
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/eventstore/memory"
+)
+
+// recordingStore records the version the events are fetched after
+type recordingStore struct {
+	*memory.Memory
+	lock  sync.Mutex
+	after []core.Version
+}
+
+func (s *recordingStore) Get(ctx context.Context, id string, aggregateType string, afterVersion core.Version) (core.Iterator, error) {
+	s.lock.Lock()
+	s.after = append(s.after, afterVersion)
+	s.lock.Unlock()
+	return s.Memory.Get(ctx, id, aggregateType, afterVersion)
+}
+
+func (s *recordingStore) last() core.Version {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.after[len(s.after)-1]
+}
+
+func TestCachedRepository(t *testing.T) {
+	es := &recordingStore{Memory: memory.Create()}
+	aggregate.Register(&Person{})
+	r, err := aggregate.NewCachedRepository(es, nil, 1, &Person{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+	err = r.Save(person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the saved state is cached and only the events after it are fetched
+	twin := Person{}
+	err = r.Load(context.Background(), person.ID(), &twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if es.last() != 2 {
+		t.Fatalf("expected events fetched after version 2 got %d", es.last())
+	}
+	if twin.Name != "kalle" || twin.Age != 1 || twin.Version() != 2 || twin.ID() != person.ID() {
+		t.Fatalf("expected kalle aged 1 in version 2 got %s aged %d in version %d", twin.Name, twin.Age, twin.Version())
+	}
+
+	// events saved outside the repository are fetched after the cached version
+	other := Person{}
+	err = aggregate.Load(context.Background(), es, person.ID(), &other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.GrowOlder()
+	err = aggregate.Save(es, &other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	twin = Person{}
+	err = r.Load(context.Background(), person.ID(), &twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if es.last() != 2 || twin.Age != 2 || twin.Version() != 3 {
+		t.Fatalf("expected age 2 in version 3 fetched after version 2 got age %d in version %d fetched after %d", twin.Age, twin.Version(), es.last())
+	}
+
+	// each load builds its own instance
+	twin.Name = "changed"
+	twin2 := Person{}
+	err = r.Load(context.Background(), person.ID(), &twin2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if es.last() != 3 || twin2.Name != "kalle" {
+		t.Fatalf("expected kalle fetched after version 3 got %s fetched after %d", twin2.Name, es.last())
+	}
+
+	// a concurrency error evicts the aggregate
+	person.GrowOlder()
+	err = r.Save(person)
+	if err == nil {
+		t.Fatal("expected concurrency error")
+	}
+	err = r.Load(context.Background(), person.ID(), &Person{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if es.last() != 0 {
+		t.Fatalf("expected events fetched from the start got %d", es.last())
+	}
+
+	// the least recently used aggregate is evicted
+	anka, err := CreatePerson("anka")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.Save(anka)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.Load(context.Background(), person.ID(), &Person{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if es.last() != 0 {
+		t.Fatalf("expected events fetched from the start got %d", es.last())
+	}
+}
+
+func TestCachedRepositoryConcurrent(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+	r, err := aggregate.NewCachedRepository(es, nil, 10, &Person{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Retry = aggregate.RetryPolicy{Retries: 100, Backoff: time.Millisecond, MaxBackoff: time.Millisecond * 10}
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.Save(person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p := Person{}
+			err := r.Update(context.Background(), person.ID(), &p, func() error {
+				p.GrowOlder()
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	twin := Person{}
+	err = aggregate.Load(context.Background(), es, person.ID(), &twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if twin.Age != 10 {
+		t.Fatalf("expected age 10 got %d", twin.Age)
+	}
+}
+
+func TestCachedRepositoryTypes(t *testing.T) {
+	es := &recordingStore{Memory: memory.Create()}
+	aggregate.Register(&Person{})
+	aggregate.Register(&Counter{})
+
+	// the state of an aggregate without snapshot methods can't be copied through the cache
+	_, err := aggregate.NewCachedRepository(es, nil, 10, &Counter{})
+	if !errors.Is(err, aggregate.ErrNoSnapshotMethods) {
+		t.Fatalf("expected no snapshot methods error got %v", err)
+	}
+
+	// aggregates of other types are loaded from the event store
+	r, err := aggregate.NewCachedRepository(es, nil, 10, &Person{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter := Counter{}
+	aggregate.TrackChange(&counter, &Incremented{})
+	err = r.Save(&counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.Load(context.Background(), counter.ID(), &Counter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if es.last() != 0 {
+		t.Fatalf("expected events fetched from the start got %d", es.last())
+	}
+}
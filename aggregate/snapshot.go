@@ -20,6 +20,11 @@ type snapshot interface {
 	DeserializeSnapshot(f SnapshotUnmarshal, d []byte) error
 }
 
+// stateful is an aggregate, with or without the snapshot methods, that can be stored as a snapshot
+type stateful interface {
+	root() *Root
+}
+
 type aggregateSnapshot interface {
 	aggregate
 	snapshot
@@ -38,24 +43,13 @@ func LoadSnapshot(ctx context.Context, ss core.SnapshotStore, id string, s snaps
 	return err
 }
 
-func getSnapshot(ctx context.Context, ss core.SnapshotStore, id string, s snapshot) error {
-	snap, err := ss.Get(ctx, id, aggregateType(s))
+// getSnapshot builds the aggregate from its snapshot
+func getSnapshot(ctx context.Context, ss core.SnapshotStore, id string, a stateful) error {
+	snap, err := ss.Get(ctx, id, aggregateType(a))
 	if err != nil {
 		return err
 	}
-
-	err = s.DeserializeSnapshot(internal.SnapshotEncoder.Deserialize, snap.State)
-	if err != nil {
-		return err
-	}
-
-	// set the internal aggregate properties
-	root := s.root()
-	root.globalVersion = eventsourcing.Version(snap.GlobalVersion)
-	root.version = eventsourcing.Version(snap.Version)
-	root.id = snap.ID
-
-	return nil
+	return restore(snap, a)
 }
 
 // SaveSnapshot will only store the snapshot and will return an error if there are events that are not stored
@@ -64,21 +58,58 @@ func SaveSnapshot(ss core.SnapshotStore, s snapshot) error {
 	if len(root.Events()) > 0 {
 		return eventsourcing.ErrUnsavedEvents
 	}
+	return saveSnapshot(ss, s)
+}
 
-	state := []byte{}
-	var err error
-	state, err = s.SerializeSnapshot(internal.SnapshotEncoder.Serialize)
+// saveSnapshot stores the aggregate as a snapshot
+func saveSnapshot(ss core.SnapshotStore, a stateful) error {
+	snap, err := capture(a)
 	if err != nil {
 		return err
 	}
+	return ss.Save(snap)
+}
 
-	snapshot := core.Snapshot{
+// capture serializes the aggregate into a snapshot, with the snapshot serialization of the aggregate if it has
+// one or else with the snapshot encoder
+func capture(a stateful) (core.Snapshot, error) {
+	var state []byte
+	var err error
+	if s, ok := a.(snapshot); ok {
+		state, err = s.SerializeSnapshot(internal.SnapshotEncoder.Serialize)
+	} else {
+		state, err = internal.SnapshotEncoder.Serialize(a)
+	}
+	if err != nil {
+		return core.Snapshot{}, err
+	}
+	root := a.root()
+	return core.Snapshot{
 		ID:            root.ID(),
-		Type:          aggregateType(s),
+		Type:          aggregateType(a),
 		Version:       core.Version(root.Version()),
 		GlobalVersion: core.Version(root.GlobalVersion()),
 		State:         state,
+	}, nil
+}
+
+// restore builds the aggregate from the snapshot, with the snapshot serialization of the aggregate if it has one
+// or else with the snapshot encoder
+func restore(snap core.Snapshot, a stateful) error {
+	var err error
+	if s, ok := a.(snapshot); ok {
+		err = s.DeserializeSnapshot(internal.SnapshotEncoder.Deserialize, snap.State)
+	} else {
+		err = internal.SnapshotEncoder.Deserialize(snap.State, a)
+	}
+	if err != nil {
+		return err
 	}
 
-	return ss.Save(snapshot)
+	// set the internal aggregate properties
+	root := a.root()
+	root.globalVersion = eventsourcing.Version(snap.GlobalVersion)
+	root.version = eventsourcing.Version(snap.Version)
+	root.id = snap.ID
+	return nil
 }
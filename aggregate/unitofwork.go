@@ -0,0 +1,107 @@
+package aggregate
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/r23vme/eventsourcing"
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/internal"
+)
+
+// ErrUnitOfWorkNotSupported is returned when a unit of work holding events of several aggregates is committed to an
+// event store that can't save them atomically
+var ErrUnitOfWorkNotSupported = errors.New("event store can't save several aggregates atomically")
+
+// UnitOfWork tracks the aggregates changed in one business operation and saves their events together. The events
+// of all tracked aggregates are saved atomically, if the events of one aggregate can't be saved no events are
+// saved. A unit of work is not safe for concurrent use.
+type UnitOfWork struct {
+	es         core.EventStore
+	aggregates []aggregate
+}
+
+// NewUnitOfWork creates a unit of work saving to the event store. The event store has to implement
+// core.MultiStreamSaver to commit events of more than one aggregate.
+func NewUnitOfWork(es core.EventStore) *UnitOfWork {
+	return &UnitOfWork{es: es}
+}
+
+// Track adds the aggregates to the unit of work, an aggregate already tracked is only added once
+func (u *UnitOfWork) Track(aggregates ...aggregate) {
+	for _, a := range aggregates {
+		if !u.tracked(a) {
+			u.aggregates = append(u.aggregates, a)
+		}
+	}
+}
+
+// Commit saves the events of the tracked aggregates in one transaction and stops tracking them. ErrConcurrency is
+// returned if another process has saved events on one of the aggregates, in that case none of the aggregates are
+// saved and they still hold their events. ErrUnitOfWorkNotSupported is returned if more than one aggregate holds
+// events and the event store can't save them atomically.
+func (u *UnitOfWork) Commit() error {
+	var pending []aggregate
+	var streams [][]core.Event
+	for _, a := range u.aggregates {
+		root := a.root()
+		if len(root.events) == 0 {
+			continue
+		}
+		if !internal.GlobalRegister.AggregateRegistered(a) {
+			return fmt.Errorf("%s %w", aggregateType(a), eventsourcing.ErrAggregateNotRegistered)
+		}
+		events, err := toCoreEvents(root.Events())
+		if err != nil {
+			return err
+		}
+		pending = append(pending, a)
+		streams = append(streams, events)
+	}
+
+	if len(streams) == 0 {
+		u.aggregates = nil
+		return nil
+	}
+
+	var err error
+	switch s := u.es.(type) {
+	case core.MultiStreamSaver:
+		err = s.SaveStreams(streams)
+	default:
+		if len(streams) > 1 {
+			return fmt.Errorf("%T %w", u.es, ErrUnitOfWorkNotSupported)
+		}
+		// the events of a single aggregate are saved atomically by any event store
+		for _, events := range streams {
+			err = u.es.Save(events)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, core.ErrConcurrency) {
+			return eventsourcing.ErrConcurrency
+		}
+		return fmt.Errorf("error from event store: %w", err)
+	}
+
+	for i, a := range pending {
+		root := a.root()
+		events := streams[i]
+		// set internal properties and reset the events slice
+		root.globalVersion = eventsourcing.Version(events[len(events)-1].GlobalVersion)
+		root.version = eventsourcing.Version(events[len(events)-1].Version)
+		root.events = []eventsourcing.Event{}
+	}
+	u.aggregates = nil
+	return nil
+}
+
+// tracked reports if the aggregate is tracked by the unit of work
+func (u *UnitOfWork) tracked(a aggregate) bool {
+	for _, t := range u.aggregates {
+		if t.root() == a.root() {
+			return true
+		}
+	}
+	return false
+}
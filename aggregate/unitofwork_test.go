@@ -0,0 +1,132 @@
+package aggregate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/r23vme/eventsourcing"
+	"github.com/r23vme/eventsourcing/aggregate"
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/eventstore/memory"
+)
+
+// singleStreamStore hides the SaveStreams method of the memory event store
+type singleStreamStore struct {
+	core.EventStore
+}
+
+func TestUnitOfWork(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	kalle, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	anka, err := CreatePerson("anka")
+	if err != nil {
+		t.Fatal(err)
+	}
+	anka.GrowOlder()
+
+	uow := aggregate.NewUnitOfWork(es)
+	uow.Track(kalle, anka, kalle)
+	err = uow.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kalle.Events()) != 0 || len(anka.Events()) != 0 {
+		t.Fatal("expected the events to be saved")
+	}
+	if kalle.Version() != 1 || anka.Version() != 2 || anka.GlobalVersion() != 3 {
+		t.Fatalf("expected versions 1 and 2 and global version 3 got %d, %d and %d", kalle.Version(), anka.Version(), anka.GlobalVersion())
+	}
+
+	twin := Person{}
+	err = aggregate.Load(context.Background(), es, anka.ID(), &twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if twin.Age != 1 {
+		t.Fatalf("expected age 1 got %d", twin.Age)
+	}
+}
+
+func TestUnitOfWorkConcurrency(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	kalle, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.Save(es, kalle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// another process saves events on kalle
+	other := Person{}
+	err = aggregate.Load(context.Background(), es, kalle.ID(), &other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.GrowOlder()
+	err = aggregate.Save(es, &other)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	anka, err := CreatePerson("anka")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kalle.GrowOlder()
+
+	uow := aggregate.NewUnitOfWork(es)
+	uow.Track(anka, kalle)
+	err = uow.Commit()
+	if !errors.Is(err, eventsourcing.ErrConcurrency) {
+		t.Fatalf("expected concurrency error got %v", err)
+	}
+	if len(anka.Events()) != 1 || len(kalle.Events()) != 1 {
+		t.Fatal("expected the aggregates to hold their events")
+	}
+	err = aggregate.Load(context.Background(), es, anka.ID(), &Person{})
+	if !errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		t.Fatalf("expected anka not to be saved got %v", err)
+	}
+}
+
+func TestUnitOfWorkNotSupported(t *testing.T) {
+	es := singleStreamStore{memory.Create()}
+	aggregate.Register(&Person{})
+
+	kalle, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	anka, err := CreatePerson("anka")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uow := aggregate.NewUnitOfWork(es)
+	uow.Track(kalle, anka)
+	err = uow.Commit()
+	if !errors.Is(err, aggregate.ErrUnitOfWorkNotSupported) {
+		t.Fatalf("expected not supported error got %v", err)
+	}
+
+	// the events of a single aggregate can be saved by any event store
+	uow = aggregate.NewUnitOfWork(es)
+	uow.Track(kalle)
+	err = uow.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kalle.Version() != 1 || len(kalle.Events()) != 0 {
+		t.Fatalf("expected kalle to be saved in version 1 got %d", kalle.Version())
+	}
+}
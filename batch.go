@@ -0,0 +1,162 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+type batchCallbackFunc func(ctx context.Context, events []Event) error
+
+// BatchError is returned from a batch callback when the batch failed on the event at Index. The events
+// before Index are treated as handled.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch failed on event %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// NewBatchProjection creates a projection where the callback receives the events in batches of up to
+// BatchSize events. Default batch size is 100.
+func NewBatchProjection(fetchF core.Fetcher, callbackF batchCallbackFunc) *Projection {
+	projection := NewProjectionContext(fetchF, nil)
+	projection.batchF = callbackF
+	projection.BatchSize = 100
+	return projection
+}
+
+// NewCheckpointBatchProjection creates a batch projection that resumes from the checkpoint stored on its name
+// in the checkpoint store.
+func NewCheckpointBatchProjection(name string, cs core.CheckpointStore, fetchFrom core.FetcherFrom, callbackF batchCallbackFunc) *Projection {
+	projection := NewCheckpointProjectionContext(name, cs, fetchFrom, nil)
+	projection.batchF = callbackF
+	projection.BatchSize = 100
+	return projection
+}
+
+// collect adds the event to the batch and handles the batch when it's full
+func (p *Projection) collect(ctx context.Context, event Event) (Event, error) {
+	if len(p.batch) == 0 {
+		p.batchStart = time.Now()
+	}
+	p.batch = append(p.batch, event)
+	if len(p.batch) < p.BatchSize {
+		return Event{}, nil
+	}
+	return p.flush(ctx)
+}
+
+// batchDue returns true if there are collected events and the linger time has passed. Without a linger time
+// the batch is handled when it's full or the end of the event stream is reached.
+func (p *Projection) batchDue() bool {
+	return len(p.batch) > 0 && p.BatchLinger > 0 && time.Since(p.batchStart) >= p.BatchLinger
+}
+
+// wait returns the time to wait before the next run, shorter than the pace if a collected batch is due before
+func (p *Projection) wait(pace time.Duration) time.Duration {
+	if len(p.batch) == 0 {
+		return pace
+	}
+	linger := p.BatchLinger - time.Since(p.batchStart)
+	if linger < pace {
+		return linger
+	}
+	return pace
+}
+
+// drain handles the collected events in the batch if the run was successful
+func (p *Projection) drain(ctx context.Context, result ProjectionResult) ProjectionResult {
+	if result.Error != nil || len(p.batch) == 0 {
+		return result
+	}
+	last, err := p.flush(ctx)
+	if last.GlobalVersion() != 0 {
+		result.LastHandledEvent = last
+	}
+	p.setPosition(last)
+	result.Error = err
+	return result
+}
+
+// flush calls the batch callback with the collected events and returns the last handled event. When the retries
+// are exhausted and the error policy has a dead-letter store the failing event is dead-lettered, or the whole
+// batch if the callback did not return a BatchError, and the events after it are handled.
+func (p *Projection) flush(ctx context.Context) (Event, error) {
+	var last Event
+	for len(p.batch) > 0 {
+		batch := p.batch
+		p.batch = nil
+
+		failed := 0
+		attempts, err := p.retry(ctx, func(ctx context.Context) error {
+			ctx, cancel := p.eventContext(ctx)
+			defer cancel()
+
+			err := p.batchF(ctx, batch)
+			handled := len(batch)
+			failed = 0
+			var batchErr *BatchError
+			if errors.As(err, &batchErr) && batchErr.Index >= 0 && batchErr.Index < len(batch) {
+				handled = batchErr.Index
+				failed = 1
+			} else if err != nil {
+				// an index outside the batch fails the whole batch
+				handled = 0
+				failed = len(batch)
+			}
+			// continue with the events after the ones handled
+			if handled > 0 {
+				last = batch[handled-1]
+				if p.checkpoints != nil {
+					p.track(last, uint64(handled))
+				}
+				batch = batch[handled:]
+			}
+			return err
+		})
+		if err == nil {
+			break
+		}
+		// a dry run reports the error instead of storing a dead letter
+		if p.OnError.DeadLetter == nil || p.DryRun {
+			if p.fetchFrom == nil {
+				// keep the events that was not handled to the next run
+				p.batch = batch
+				return last, err
+			}
+			// the events after the last handled event have been fetched, continue from the last handled event on next run
+			if cpErr := p.saveCheckpoint(); cpErr != nil {
+				return last, cpErr
+			}
+			p.fetchF = p.from(p.pending + 1)
+			return last, err
+		}
+		for _, event := range batch[:failed] {
+			dlErr := p.deadLetter(event, err, attempts)
+			if dlErr != nil {
+				p.batch = batch
+				return last, dlErr
+			}
+		}
+		// the dead-lettered events are skipped as handled
+		last = batch[failed-1]
+		if p.checkpoints != nil {
+			p.track(last, uint64(failed))
+		}
+		p.batch = batch[failed:]
+	}
+	if p.checkpointDue() {
+		return last, p.saveCheckpoint()
+	}
+	return last, nil
+}
@@ -0,0 +1,80 @@
+package bbolt
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+const checkpointBucketName = "checkpoints"
+
+// BBolt is the checkpoint store handler
+type BBolt struct {
+	db *bbolt.DB
+}
+
+// New binds the checkpoint store to the bbolt database. The database can be shared with the bbolt
+// event store making it possible to store read models, checkpoints and events in the same file.
+func New(db *bbolt.DB) (*BBolt, error) {
+	err := db.Update(func(tx *bbolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists([]byte(checkpointBucketName)); err != nil {
+			return errors.New("could not create checkpoint bucket")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &BBolt{
+		db: db,
+	}, nil
+}
+
+// Close closes the underlying database
+func (b *BBolt) Close() error {
+	return b.db.Close()
+}
+
+// Save stores the checkpoint for the projection name
+func (b *BBolt) Save(name string, version core.Version) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		return save(tx, name, version)
+	})
+}
+
+// Load returns the checkpoint stored for the projection name
+func (b *BBolt) Load(ctx context.Context, name string) (core.Version, error) {
+	var version core.Version
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(checkpointBucketName))
+		if bucket == nil {
+			return core.ErrCheckpointNotFound
+		}
+		value := bucket.Get([]byte(name))
+		if value == nil {
+			return core.ErrCheckpointNotFound
+		}
+		version = core.Version(binary.BigEndian.Uint64(value))
+		return nil
+	})
+	return version, err
+}
+
+// save puts the checkpoint into the checkpoint bucket in the transaction
+func save(tx *bbolt.Tx, name string, version core.Version) error {
+	bucket := tx.Bucket([]byte(checkpointBucketName))
+	if bucket == nil {
+		return errors.New("checkpoint bucket not found")
+	}
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(version))
+	if err := bucket.Put([]byte(name), b); err != nil {
+		return fmt.Errorf("could not save checkpoint for %s, %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package bbolt_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	cs "github.com/r23vme/eventsourcing/checkpointstore/bbolt"
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/core/testsuite"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (core.CheckpointStore, func(), error) {
+		dbFile := "checkpoint.db"
+		db, err := bbolt.Open(dbFile, 0600, &bbolt.Options{Timeout: time.Second})
+		if err != nil {
+			return nil, nil, err
+		}
+		store, err := cs.New(db)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() {
+			store.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	testsuite.TestCheckpointStore(t, f)
+}
@@ -0,0 +1,58 @@
+package bbolt
+
+import (
+	"context"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/r23vme/eventsourcing"
+	"github.com/r23vme/eventsourcing/core"
+)
+
+// TxCallback handles an event in the same transaction as the checkpoint is stored
+type TxCallback func(tx *bbolt.Tx, event eventsourcing.Event) error
+
+type transactor struct {
+	*BBolt
+	f TxCallback
+}
+
+// Transactor returns a transactor for transactional projections where the callback and the checkpoint
+// share the same bbolt read-write transaction
+func (b *BBolt) Transactor(f TxCallback) eventsourcing.Transactor {
+	return &transactor{BBolt: b, f: f}
+}
+
+// Begin starts a read-write transaction
+func (t *transactor) Begin(ctx context.Context) (eventsourcing.Transaction, error) {
+	tx, err := t.db.Begin(true)
+	if err != nil {
+		return nil, err
+	}
+	return &transaction{tx: tx, f: t.f}, nil
+}
+
+type transaction struct {
+	tx *bbolt.Tx
+	f  TxCallback
+}
+
+// Handle calls the callback with the bbolt transaction
+func (t *transaction) Handle(event eventsourcing.Event) error {
+	return t.f(t.tx, event)
+}
+
+// Commit stores the checkpoint and commits the bbolt transaction
+func (t *transaction) Commit(name string, checkpoint core.Version) error {
+	err := save(t.tx, name, checkpoint)
+	if err != nil {
+		t.tx.Rollback()
+		return err
+	}
+	return t.tx.Commit()
+}
+
+// Rollback aborts the bbolt transaction
+func (t *transaction) Rollback() error {
+	return t.tx.Rollback()
+}
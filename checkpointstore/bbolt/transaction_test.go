@@ -0,0 +1,103 @@
+package bbolt_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/r23vme/eventsourcing"
+	"github.com/r23vme/eventsourcing/aggregate"
+	cs "github.com/r23vme/eventsourcing/checkpointstore/bbolt"
+	"github.com/r23vme/eventsourcing/core"
+	esbbolt "github.com/r23vme/eventsourcing/eventstore/bbolt"
+)
+
+type Person struct {
+	aggregate.Root
+	Name string
+}
+
+type Born struct {
+	Name string
+}
+
+func (p *Person) Transition(event eventsourcing.Event) {
+	switch e := event.Data().(type) {
+	case *Born:
+		p.Name = e.Name
+	}
+}
+
+func (p *Person) Register(f aggregate.RegisterFunc) {
+	f(&Born{})
+}
+
+func TestTxProjection(t *testing.T) {
+	es, err := esbbolt.New(filepath.Join(t.TempDir(), "bolt.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer es.Close()
+
+	// the checkpoint store share the database with the event store
+	store, err := cs.New(es.DB())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aggregate.Register(&Person{})
+	for _, name := range []string{"kalle", "anka", "musse"} {
+		person := &Person{}
+		aggregate.TrackChange(person, &Born{Name: name})
+		err = aggregate.Save(es, person)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	callback := func(tx *bbolt.Tx, event eventsourcing.Event) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte("persons"))
+		if err != nil {
+			return err
+		}
+		switch e := event.Data().(type) {
+		case *Born:
+			return bucket.Put([]byte(event.AggregateID()), []byte(e.Name))
+		}
+		return nil
+	}
+
+	p := eventsourcing.NewTxProjection("persons", store.Transactor(callback), func(start core.Version) core.Fetcher {
+		return es.All(start)
+	})
+	p.CheckpointEvery = 2
+
+	result := p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	count := 0
+	err = es.DB().View(func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte("persons")).ForEach(func(k, v []byte) error {
+			count++
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Fatalf("expected 3 persons got %d", count)
+	}
+
+	checkpoint, err := store.Load(context.Background(), "persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint != 3 {
+		t.Fatalf("expected checkpoint 3 got %d", checkpoint)
+	}
+}
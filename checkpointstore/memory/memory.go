@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+type Memory struct {
+	checkpoints map[string]core.Version
+	lock        sync.Mutex
+}
+
+// Create in memory checkpoint store
+func Create() *Memory {
+	return &Memory{
+		checkpoints: make(map[string]core.Version),
+	}
+}
+
+func (m *Memory) Close() {
+
+}
+
+// Load returns the checkpoint stored for the projection name
+func (m *Memory) Load(ctx context.Context, name string) (core.Version, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	version, ok := m.checkpoints[name]
+	if !ok {
+		return 0, core.ErrCheckpointNotFound
+	}
+	return version, nil
+}
+
+// Save stores the checkpoint for the projection name
+func (m *Memory) Save(name string, version core.Version) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.checkpoints[name] = version
+	return nil
+}
@@ -0,0 +1,17 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/r23vme/eventsourcing/checkpointstore/memory"
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/core/testsuite"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (core.CheckpointStore, func(), error) {
+		cs := memory.Create()
+		return cs, func() { cs.Close() }, nil
+	}
+	testsuite.TestCheckpointStore(t, f)
+}
@@ -0,0 +1,72 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+const createTableSQLite = `
+CREATE TABLE IF NOT EXISTS checkpoints (
+	name     VARCHAR NOT NULL PRIMARY KEY,
+	version  INTEGER
+);`
+
+type SQLite struct {
+	db *sql.DB
+}
+
+// NewSQLite connection to database
+func NewSQLite(db *sql.DB) (*SQLite, error) {
+	if err := migrate(db, []string{
+		createTableSQLite,
+	}); err != nil {
+		return nil, err
+	}
+	return &SQLite{
+		db: db,
+	}, nil
+}
+
+// Close the connection
+func (s *SQLite) Close() {
+	s.db.Close()
+}
+
+// Save persists the checkpoint
+func (s *SQLite) Save(name string, version core.Version) error {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return errors.New(fmt.Sprintf("could not start a write transaction, %v", err))
+	}
+	defer tx.Rollback()
+
+	err = s.saveTx(tx, name, version)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Load return the checkpoint from the database
+func (s *SQLite) Load(ctx context.Context, name string) (core.Version, error) {
+	var version core.Version
+	selectStm := `Select version from checkpoints where name=?`
+	err := s.db.QueryRowContext(ctx, selectStm, name).Scan(&version)
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return 0, core.ErrCheckpointNotFound
+	} else if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// saveTx upserts the checkpoint in the transaction
+func (s *SQLite) saveTx(tx *sql.Tx, name string, version core.Version) error {
+	statement := `INSERT INTO checkpoints (name, version) VALUES ($1, $2) ON CONFLICT(name) DO UPDATE SET version=excluded.version`
+	_, err := tx.Exec(statement, name, version)
+	return err
+}
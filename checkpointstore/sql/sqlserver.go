@@ -0,0 +1,78 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+const createTableSQLServer = `IF OBJECT_ID('[checkpoints]', 'U') IS NULL
+BEGIN
+    CREATE TABLE [checkpoints] (
+        [name] NVARCHAR(255) NOT NULL PRIMARY KEY,
+        [version] BIGINT
+    );
+END`
+
+type SQLServer struct {
+	db *sql.DB
+}
+
+// NewSQLServer connection to database
+func NewSQLServer(db *sql.DB) (*SQLServer, error) {
+	if err := migrate(db, []string{
+		createTableSQLServer,
+	}); err != nil {
+		return nil, err
+	}
+	return &SQLServer{
+		db: db,
+	}, nil
+}
+
+// Close the connection
+func (s *SQLServer) Close() {
+	s.db.Close()
+}
+
+// Save persists the checkpoint
+func (s *SQLServer) Save(name string, version core.Version) error {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return errors.New(fmt.Sprintf("could not start a write transaction, %v", err))
+	}
+	defer tx.Rollback()
+
+	err = s.saveTx(tx, name, version)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Load return the checkpoint from the database
+func (s *SQLServer) Load(ctx context.Context, name string) (core.Version, error) {
+	var version core.Version
+	selectStm := `SELECT [version] FROM [checkpoints] WHERE [name] = @name;`
+	err := s.db.QueryRowContext(ctx, selectStm, sql.Named("name", name)).Scan(&version)
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return 0, core.ErrCheckpointNotFound
+	} else if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// saveTx upserts the checkpoint in the transaction
+func (s *SQLServer) saveTx(tx *sql.Tx, name string, version core.Version) error {
+	statement := `MERGE [checkpoints] WITH (HOLDLOCK) AS target
+USING (SELECT @name AS [name], @version AS [version]) AS source
+ON target.[name] = source.[name]
+WHEN MATCHED THEN UPDATE SET [version] = source.[version]
+WHEN NOT MATCHED THEN INSERT ([name], [version]) VALUES (source.[name], source.[version]);`
+	_, err := tx.Exec(statement, sql.Named("name", name), sql.Named("version", int64(version)))
+	return err
+}
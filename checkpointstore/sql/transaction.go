@@ -0,0 +1,75 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/r23vme/eventsourcing"
+	"github.com/r23vme/eventsourcing/core"
+)
+
+// TxCallback handles an event in the same transaction as the checkpoint is stored
+type TxCallback func(tx *sql.Tx, event eventsourcing.Event) error
+
+type saveTxFunc func(tx *sql.Tx, name string, version core.Version) error
+
+// transactor begin transactions on the database used by the checkpoint store
+type transactor struct {
+	core.CheckpointStore
+	db     *sql.DB
+	saveTx saveTxFunc
+	f      TxCallback
+}
+
+// Begin starts a database transaction
+func (t *transactor) Begin(ctx context.Context) (eventsourcing.Transaction, error) {
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &transaction{tx: tx, saveTx: t.saveTx, f: t.f}, nil
+}
+
+type transaction struct {
+	tx     *sql.Tx
+	saveTx saveTxFunc
+	f      TxCallback
+}
+
+// Handle calls the callback with the database transaction
+func (t *transaction) Handle(event eventsourcing.Event) error {
+	return t.f(t.tx, event)
+}
+
+// Commit stores the checkpoint and commits the database transaction
+func (t *transaction) Commit(name string, checkpoint core.Version) error {
+	err := t.saveTx(t.tx, name, checkpoint)
+	if err != nil {
+		t.tx.Rollback()
+		return err
+	}
+	return t.tx.Commit()
+}
+
+// Rollback aborts the database transaction
+func (t *transaction) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// Transactor returns a transactor for transactional projections where the callback and the checkpoint
+// share the same SQLite transaction
+func (s *SQLite) Transactor(f TxCallback) eventsourcing.Transactor {
+	return &transactor{CheckpointStore: s, db: s.db, saveTx: s.saveTx, f: f}
+}
+
+// Transactor returns a transactor for transactional projections where the callback and the checkpoint
+// share the same Postgres transaction
+func (s *Postgres) Transactor(f TxCallback) eventsourcing.Transactor {
+	return &transactor{CheckpointStore: s, db: s.db, saveTx: s.saveTx, f: f}
+}
+
+// Transactor returns a transactor for transactional projections where the callback and the checkpoint
+// share the same SQL Server transaction
+func (s *SQLServer) Transactor(f TxCallback) eventsourcing.Transactor {
+	return &transactor{CheckpointStore: s, db: s.db, saveTx: s.saveTx, f: f}
+}
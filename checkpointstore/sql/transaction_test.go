@@ -0,0 +1,105 @@
+package sql_test
+
+import (
+	"context"
+	sqldriver "database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/r23vme/eventsourcing"
+	"github.com/r23vme/eventsourcing/aggregate"
+	"github.com/r23vme/eventsourcing/checkpointstore/sql"
+	"github.com/r23vme/eventsourcing/core"
+	essql "github.com/r23vme/eventsourcing/eventstore/sql"
+)
+
+type Person struct {
+	aggregate.Root
+	Name string
+}
+
+type Born struct {
+	Name string
+}
+
+func (p *Person) Transition(event eventsourcing.Event) {
+	switch e := event.Data().(type) {
+	case *Born:
+		p.Name = e.Name
+	}
+}
+
+func (p *Person) Register(f aggregate.RegisterFunc) {
+	f(&Born{})
+}
+
+func TestTxProjectionSQLite(t *testing.T) {
+	// WAL mode makes it possible to read events while the read model is written
+	dsn := "file:" + filepath.Join(t.TempDir(), "test.db") + "?_journal_mode=WAL"
+	db, err := sqldriver.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	es, err := essql.NewSQLite(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := sql.NewSQLite(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`CREATE TABLE persons (id VARCHAR PRIMARY KEY, name VARCHAR)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aggregate.Register(&Person{})
+	for _, name := range []string{"kalle", "anka", "musse"} {
+		person := &Person{}
+		aggregate.TrackChange(person, &Born{Name: name})
+		err = aggregate.Save(es, person)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	callback := func(tx *sqldriver.Tx, event eventsourcing.Event) error {
+		switch e := event.Data().(type) {
+		case *Born:
+			_, err := tx.Exec(`INSERT INTO persons (id, name) VALUES (?, ?)`, event.AggregateID(), e.Name)
+			return err
+		}
+		return nil
+	}
+
+	p := eventsourcing.NewTxProjection("persons", cs.Transactor(callback), func(start core.Version) core.Fetcher {
+		return es.All(start)
+	})
+	p.CheckpointEvery = 2
+
+	result := p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	var count int
+	err = db.QueryRow(`SELECT count(*) FROM persons`).Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Fatalf("expected 3 persons got %d", count)
+	}
+
+	checkpoint, err := cs.Load(context.Background(), "persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint != 3 {
+		t.Fatalf("expected checkpoint 3 got %d", checkpoint)
+	}
+}
@@ -0,0 +1,79 @@
+// Package command dispatches commands to the handler registered on the command type. The handlers get an
+// aggregate repository scoped to the stores the bus is created with and are wrapped by the middleware on the bus.
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/r23vme/eventsourcing/aggregate"
+)
+
+// ErrHandlerNotFound is returned when a command without a registered handler is dispatched
+var ErrHandlerNotFound = errors.New("command handler not found")
+
+// Handler handles a command with the aggregates in the repository
+type Handler func(ctx context.Context, r *aggregate.Repository, cmd interface{}) error
+
+// Middleware wraps a handler, ex. to validate, log or authorize the command before the next handler is called
+type Middleware func(next Handler) Handler
+
+// Bus dispatches commands to the handlers registered on their types
+type Bus struct {
+	repository *aggregate.Repository
+	lock       sync.RWMutex
+	handlers   map[reflect.Type]Handler
+	middleware []Middleware
+}
+
+// NewBus creates a bus where the handlers get the repository
+func NewBus(r *aggregate.Repository) *Bus {
+	return &Bus{
+		repository: r,
+		handlers:   make(map[reflect.Type]Handler),
+	}
+}
+
+// Handle registers the handler for the command type C. The command is dispatched to the handler when its type
+// is exactly C, a handler registered on CreatePerson does not handle *CreatePerson. A handler registered again
+// on the same type replaces the previous handler.
+func Handle[C any](b *Bus, handler func(ctx context.Context, r *aggregate.Repository, cmd C) error) *Bus {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.handlers[reflect.TypeOf((*C)(nil)).Elem()] = func(ctx context.Context, r *aggregate.Repository, cmd interface{}) error {
+		return handler(ctx, r, cmd.(C))
+	}
+	return b
+}
+
+// Use adds middleware around the handlers, the first added middleware is the outermost and called first
+func (b *Bus) Use(middleware ...Middleware) *Bus {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.middleware = append(b.middleware, middleware...)
+	return b
+}
+
+// Dispatch calls the handler registered on the type of the command through the middleware
+func (b *Bus) Dispatch(ctx context.Context, cmd interface{}) error {
+	b.lock.RLock()
+	handler, ok := b.handlers[reflect.TypeOf(cmd)]
+	middleware := slices.Clone(b.middleware)
+	b.lock.RUnlock()
+	if !ok {
+		return fmt.Errorf("%T %w", cmd, ErrHandlerNotFound)
+	}
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler(ctx, b.repository, cmd)
+}
+
+// Repository returns the repository passed to the handlers
+func (b *Bus) Repository() *aggregate.Repository {
+	return b.repository
+}
@@ -0,0 +1,139 @@
+package command_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/r23vme/eventsourcing"
+	"github.com/r23vme/eventsourcing/aggregate"
+	"github.com/r23vme/eventsourcing/command"
+	"github.com/r23vme/eventsourcing/eventstore/memory"
+)
+
+// Person aggregate
+type Person struct {
+	aggregate.Root
+	Name string
+	Age  int
+}
+
+// Born event
+type Born struct {
+	Name string
+}
+
+// AgedOneYear event
+type AgedOneYear struct{}
+
+func (person *Person) Transition(event eventsourcing.Event) {
+	switch e := event.Data().(type) {
+	case *Born:
+		person.Name = e.Name
+	case *AgedOneYear:
+		person.Age++
+	}
+}
+
+func (person *Person) Register(f aggregate.RegisterFunc) {
+	f(&Born{}, &AgedOneYear{})
+}
+
+// CreatePerson command
+type CreatePerson struct {
+	ID   string
+	Name string
+}
+
+// GrowOlder command
+type GrowOlder struct {
+	ID string
+}
+
+var errBlankName = errors.New("name can't be blank")
+
+func TestBus(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	bus := command.NewBus(aggregate.NewRepository(es, nil))
+	command.Handle(bus, func(ctx context.Context, r *aggregate.Repository, cmd CreatePerson) error {
+		person := Person{}
+		return r.Create(ctx, &person, func() error {
+			err := person.SetID(cmd.ID)
+			if err != nil {
+				return err
+			}
+			aggregate.TrackChange(&person, &Born{Name: cmd.Name})
+			return nil
+		})
+	})
+	command.Handle(bus, func(ctx context.Context, r *aggregate.Repository, cmd GrowOlder) error {
+		person := Person{}
+		return r.Update(ctx, cmd.ID, &person, func() error {
+			aggregate.TrackChange(&person, &AgedOneYear{})
+			return nil
+		})
+	})
+
+	var calls []string
+	logging := func(name string) command.Middleware {
+		return func(next command.Handler) command.Handler {
+			return func(ctx context.Context, r *aggregate.Repository, cmd interface{}) error {
+				calls = append(calls, name)
+				return next(ctx, r, cmd)
+			}
+		}
+	}
+	validation := func(next command.Handler) command.Handler {
+		return func(ctx context.Context, r *aggregate.Repository, cmd interface{}) error {
+			if c, ok := cmd.(CreatePerson); ok && c.Name == "" {
+				return errBlankName
+			}
+			return next(ctx, r, cmd)
+		}
+	}
+	bus.Use(logging("first"), logging("second"), validation)
+
+	err := bus.Dispatch(context.Background(), CreatePerson{ID: "123", Name: "kalle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = bus.Dispatch(context.Background(), GrowOlder{ID: "123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	person := Person{}
+	err = bus.Repository().Load(context.Background(), "123", &person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if person.Name != "kalle" || person.Age != 1 {
+		t.Fatalf("expected kalle aged 1 got %s aged %d", person.Name, person.Age)
+	}
+	expected := []string{"first", "second", "first", "second"}
+	if len(calls) != len(expected) {
+		t.Fatalf("expected middleware calls %v got %v", expected, calls)
+	}
+	for i := range calls {
+		if calls[i] != expected[i] {
+			t.Fatalf("expected middleware calls %v got %v", expected, calls)
+		}
+	}
+
+	// the middleware stops the command before the handler
+	err = bus.Dispatch(context.Background(), CreatePerson{ID: "456"})
+	if !errors.Is(err, errBlankName) {
+		t.Fatalf("expected blank name error got %v", err)
+	}
+	err = bus.Repository().Load(context.Background(), "456", &Person{})
+	if !errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		t.Fatalf("expected aggregate not found got %v", err)
+	}
+
+	// the handler is registered on the exact command type
+	err = bus.Dispatch(context.Background(), &GrowOlder{ID: "123"})
+	if !errors.Is(err, command.ErrHandlerNotFound) {
+		t.Fatalf("expected handler not found got %v", err)
+	}
+}
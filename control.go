@@ -0,0 +1,119 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+// ErrNotSeekable is returned when seeking a projection that is not created with a core.FetcherFrom
+var ErrNotSeekable = errors.New("projection is not seekable")
+
+// Pause makes a running projection stop fetching events until Resume is called. The fetched events are
+// handled and the checkpoint stored before the projection pauses.
+func (p *Projection) Pause() {
+	p.paused.Store(true)
+}
+
+// Resume continues a paused projection
+func (p *Projection) Resume() {
+	p.paused.Store(false)
+	p.TriggerAsync()
+}
+
+// Paused returns true if the projection is paused
+func (p *Projection) Paused() bool {
+	return p.paused.Load()
+}
+
+// Seek moves the projection to continue with the event with the global version. The checkpoint is stored
+// directly, events handled and not stored in the checkpoint are discarded. It can be called on a running
+// projection and takes effect when the projection has finished handling the fetched events.
+func (p *Projection) Seek(version Version) error {
+	if p.fetchFrom == nil {
+		return ErrNotSeekable
+	}
+	p.exec.Lock()
+	defer p.exec.Unlock()
+
+	var checkpoint core.Version
+	if version > 0 {
+		checkpoint = core.Version(version) - 1
+	}
+	if p.tx != nil {
+		p.tx.Rollback()
+		p.tx = nil
+	}
+	if p.checkpoints != nil && !p.DryRun {
+		err := p.checkpoints.Save(p.Name, checkpoint)
+		if err != nil {
+			return err
+		}
+	}
+	p.batch = nil
+	p.unsaved = 0
+	p.pending = checkpoint
+	p.scanned = 0
+	p.lastHandled = Event{}
+	p.committed = Event{}
+	p.savedAt = time.Now()
+	p.handledAt.Store(0)
+	p.advance(Version(checkpoint))
+	p.fetchF = p.from(checkpoint + 1)
+	p.TriggerAsync()
+	return nil
+}
+
+// SeekTime moves the projection to continue with the first event with a timestamp at or after the time. The
+// event stream is read from the start to find the event.
+func (p *Projection) SeekTime(ctx context.Context, t time.Time) error {
+	if p.fetchFrom == nil {
+		return ErrNotSeekable
+	}
+	version, err := p.versionAt(ctx, t)
+	if err != nil {
+		return err
+	}
+	return p.Seek(Version(version))
+}
+
+// Reset moves the projection to the start of the event stream to rebuild the read model
+func (p *Projection) Reset() error {
+	return p.Seek(0)
+}
+
+// versionAt returns the global version of the first event with a timestamp at or after the time, or the
+// version after the last event if there is no such event
+func (p *Projection) versionAt(ctx context.Context, t time.Time) (core.Version, error) {
+	fetchF := p.fetchFrom(1)
+	var last core.Version
+	for {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		iterator, err := fetchF()
+		if err != nil {
+			return 0, err
+		}
+		var ran bool
+		for iterator.Next() {
+			ran = true
+			event, err := iterator.Value()
+			if err != nil {
+				iterator.Close()
+				return 0, err
+			}
+			if !event.Timestamp.Before(t) {
+				iterator.Close()
+				return event.GlobalVersion, nil
+			}
+			last = event.GlobalVersion
+		}
+		iterator.Close()
+		if !ran {
+			return last + 1, nil
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package core
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrCheckpointNotFound returned when no checkpoint is found in the checkpoint store
+var ErrCheckpointNotFound = errors.New("checkpoint not found")
+
+// CheckpointStore expose the methods a checkpoint store must uphold. A checkpoint is the global
+// version of the last event a named projection has handled.
+type CheckpointStore interface {
+	Save(name string, version Version) error
+	Load(ctx context.Context, name string) (Version, error)
+}
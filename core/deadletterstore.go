@@ -0,0 +1,23 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// DeadLetter is an event a projection failed to handle
+type DeadLetter struct {
+	Name      string    // name of the projection
+	Event     Event     // the event that failed
+	Error     string    // the last error returned when handling the event
+	Attempts  int       // number of times the event has been handled
+	Timestamp time.Time // when the event was dead-lettered
+}
+
+// DeadLetterStore expose the methods a dead-letter store must uphold. A dead letter is identified by
+// the projection name and the global version of its event.
+type DeadLetterStore interface {
+	Save(deadLetter DeadLetter) error
+	List(ctx context.Context, name string) ([]DeadLetter, error)
+	Delete(name string, globalVersion Version) error
+}
@@ -20,3 +20,18 @@ type EventStore interface {
 	Save(events []Event) error
 	Get(ctx context.Context, id string, aggregateType string, afterVersion Version) (Iterator, error)
 }
+
+// MultiStreamSaver is implemented by event stores that can save the events of several aggregates atomically,
+// either all events are saved or none
+type MultiStreamSaver interface {
+	SaveStreams(streams [][]Event) error
+}
+
+// HeadStore is implemented by event stores that can return the global version of the latest saved event
+type HeadStore interface {
+	Head(ctx context.Context) (Version, error)
+}
+
+// HeadFunc returns the global version of the latest saved event. The Head methods on the event stores
+// can be used as a HeadFunc.
+type HeadFunc func(ctx context.Context) (Version, error)
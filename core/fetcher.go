@@ -2,3 +2,23 @@ package core
 
 // Fetcher is the event fetch function concumed by projections
 type Fetcher func() (Iterator, error)
+
+// FetcherFrom returns a Fetcher that starts on the event with the start global version.
+// The All methods on the event stores can be used as a FetcherFrom.
+type FetcherFrom func(start Version) Fetcher
+
+// PositionIterator is implemented by iterators that skip events. Position returns the global version of the last
+// event read from the underlying iterator, including the skipped events.
+type PositionIterator interface {
+	Iterator
+	Position() Version
+}
+
+// IteratorPosition returns the position of the iterator if it skips events past the version, or else the version
+// of the last event read from it
+func IteratorPosition(iterator Iterator, version Version) Version {
+	if p, ok := iterator.(PositionIterator); ok && p.Position() > version {
+		return p.Position()
+	}
+	return version
+}
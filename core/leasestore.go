@@ -0,0 +1,19 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrLeaseHeld returned when the lease is held by another owner
+var ErrLeaseHeld = errors.New("lease is held by another owner")
+
+// LeaseStore expose the methods a lease store must uphold. A lease on a name is held by one owner
+// at a time until it's released or expires.
+type LeaseStore interface {
+	// Acquire takes or renews the lease for the owner, ErrLeaseHeld is returned if another owner holds the lease
+	Acquire(ctx context.Context, name, owner string, ttl time.Duration) error
+	// Release gives up the lease if it's held by the owner
+	Release(name, owner string) error
+}
@@ -0,0 +1,21 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// MemberStore expose the methods a member store must uphold. It keeps track of the live members in a group,
+// a member is live until it leaves the group or the ttl from its last heartbeat has passed.
+type MemberStore interface {
+	// Heartbeat marks the member as live and returns the live members in the group sorted by name
+	Heartbeat(ctx context.Context, group, member string, ttl time.Duration) ([]string, error)
+	// Leave removes the member from the group
+	Leave(group, member string) error
+}
+
+// Coordinator is a store used to coordinate work between processes with leases and group membership
+type Coordinator interface {
+	LeaseStore
+	MemberStore
+}
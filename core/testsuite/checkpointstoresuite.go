@@ -0,0 +1,84 @@
+package testsuite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+type checkpointstoreFunc = func() (core.CheckpointStore, func(), error)
+
+func TestCheckpointStore(t *testing.T, csFunc checkpointstoreFunc) {
+	tests := []struct {
+		title string
+		run   func(cs core.CheckpointStore) error
+	}{
+		{"should save and load checkpoint", saveAndLoadCheckpoint},
+		{"should overwrite existing checkpoint", overwriteCheckpoint},
+		{"should get error when loading none existing checkpoint", loadNoneExistingCheckpoint},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			cs, closeFunc, err := csFunc()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = test.run(cs)
+			if err != nil {
+				// make use of t.Error instead of t.Fatal to make sure the closeFunc is executed
+				t.Error(err)
+			}
+			closeFunc()
+		})
+	}
+}
+
+func saveAndLoadCheckpoint(cs core.CheckpointStore) error {
+	name := "projection_" + AggregateID()
+	err := cs.Save(name, 10)
+	if err != nil {
+		return err
+	}
+
+	version, err := cs.Load(context.Background(), name)
+	if err != nil {
+		return err
+	}
+	if version != 10 {
+		return fmt.Errorf("exp checkpoint %d got %d", 10, version)
+	}
+	return nil
+}
+
+func overwriteCheckpoint(cs core.CheckpointStore) error {
+	name := "projection_" + AggregateID()
+	err := cs.Save(name, 10)
+	if err != nil {
+		return err
+	}
+	err = cs.Save(name, 20)
+	if err != nil {
+		return err
+	}
+
+	version, err := cs.Load(context.Background(), name)
+	if err != nil {
+		return err
+	}
+	if version != 20 {
+		return fmt.Errorf("exp checkpoint %d got %d", 20, version)
+	}
+	return nil
+}
+
+func loadNoneExistingCheckpoint(cs core.CheckpointStore) error {
+	_, err := cs.Load(context.Background(), "none_existing_"+AggregateID())
+	if !errors.Is(err, core.ErrCheckpointNotFound) {
+		return fmt.Errorf("expected core.ErrCheckpointNotFound got %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package testsuite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+type deadletterstoreFunc = func() (core.DeadLetterStore, func(), error)
+
+func TestDeadLetterStore(t *testing.T, dsFunc deadletterstoreFunc) {
+	tests := []struct {
+		title string
+		run   func(ds core.DeadLetterStore) error
+	}{
+		{"should save and list dead letters in global version order", saveAndListDeadLetters},
+		{"should replace dead letter for the same event", replaceDeadLetter},
+		{"should delete dead letter", deleteDeadLetter},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			ds, closeFunc, err := dsFunc()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = test.run(ds)
+			if err != nil {
+				// make use of t.Error instead of t.Fatal to make sure the closeFunc is executed
+				t.Error(err)
+			}
+			closeFunc()
+		})
+	}
+}
+
+func deadLetter(name string, globalVersion core.Version) core.DeadLetter {
+	event := testEventOtherAggregate(AggregateID())
+	event.GlobalVersion = globalVersion
+	return core.DeadLetter{
+		Name:      name,
+		Event:     event,
+		Error:     "error",
+		Attempts:  1,
+		Timestamp: time.Now().UTC(),
+	}
+}
+
+func saveAndListDeadLetters(ds core.DeadLetterStore) error {
+	name := "projection_" + AggregateID()
+	for _, v := range []core.Version{3, 1, 2} {
+		err := ds.Save(deadLetter(name, v))
+		if err != nil {
+			return err
+		}
+	}
+	// dead letter on other projection
+	err := ds.Save(deadLetter("other_"+name, 4))
+	if err != nil {
+		return err
+	}
+
+	deadLetters, err := ds.List(context.Background(), name)
+	if err != nil {
+		return err
+	}
+	if len(deadLetters) != 3 {
+		return fmt.Errorf("expected 3 dead letters got %d", len(deadLetters))
+	}
+	for i, d := range deadLetters {
+		if d.Event.GlobalVersion != core.Version(i+1) {
+			return fmt.Errorf("expected dead letter %d to have global version %d got %d", i, i+1, d.Event.GlobalVersion)
+		}
+		if d.Name != name {
+			return fmt.Errorf("expected dead letter name %q got %q", name, d.Name)
+		}
+		if d.Event.Reason != "FrequentFlierAccountCreated" {
+			return fmt.Errorf("expected event reason FrequentFlierAccountCreated got %q", d.Event.Reason)
+		}
+		expected := deadLetter(name, d.Event.GlobalVersion)
+		if d.Event.AggregateType != expected.Event.AggregateType || d.Event.Version != expected.Event.Version {
+			return fmt.Errorf("expected event %s version %d got %s version %d", expected.Event.AggregateType, expected.Event.Version, d.Event.AggregateType, d.Event.Version)
+		}
+		if !bytes.Equal(d.Event.Data, expected.Event.Data) || !bytes.Equal(d.Event.Metadata, expected.Event.Metadata) {
+			return fmt.Errorf("expected event data %s metadata %s got %s %s", expected.Event.Data, expected.Event.Metadata, d.Event.Data, d.Event.Metadata)
+		}
+		if d.Error != "error" || d.Attempts != 1 || d.Timestamp.IsZero() || d.Event.Timestamp.IsZero() {
+			return fmt.Errorf("unexpected dead letter %#v", d)
+		}
+	}
+	return nil
+}
+
+func replaceDeadLetter(ds core.DeadLetterStore) error {
+	name := "projection_" + AggregateID()
+	d := deadLetter(name, 1)
+	err := ds.Save(d)
+	if err != nil {
+		return err
+	}
+	d.Attempts = 2
+	d.Error = "second error"
+	err = ds.Save(d)
+	if err != nil {
+		return err
+	}
+
+	deadLetters, err := ds.List(context.Background(), name)
+	if err != nil {
+		return err
+	}
+	if len(deadLetters) != 1 {
+		return fmt.Errorf("expected 1 dead letter got %d", len(deadLetters))
+	}
+	if deadLetters[0].Attempts != 2 || deadLetters[0].Error != "second error" {
+		return fmt.Errorf("expected the dead letter to be replaced got %#v", deadLetters[0])
+	}
+	return nil
+}
+
+func deleteDeadLetter(ds core.DeadLetterStore) error {
+	name := "projection_" + AggregateID()
+	err := ds.Save(deadLetter(name, 1))
+	if err != nil {
+		return err
+	}
+	err = ds.Delete(name, 1)
+	if err != nil {
+		return err
+	}
+	deadLetters, err := ds.List(context.Background(), name)
+	if err != nil {
+		return err
+	}
+	if len(deadLetters) != 0 {
+		return fmt.Errorf("expected no dead letters got %d", len(deadLetters))
+	}
+	return nil
+}
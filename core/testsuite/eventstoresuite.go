@@ -91,6 +91,8 @@ func Test(t *testing.T, esFunc eventstoreFunc) {
 		{"should save and get event concurrently", saveAndGetEventsConcurrently},
 		{"should return error when no events", getErrWhenNoEvents},
 		{"should get global event order from save", saveReturnGlobalEventOrder},
+		{"should get the global version of the latest event as head", getHead},
+		{"should save the events of several aggregates atomically", saveStreams},
 	}
 
 	for _, test := range tests {
@@ -309,6 +311,73 @@ func saveReturnGlobalEventOrder(es core.EventStore) error {
 	return nil
 }
 
+func getHead(es core.EventStore) error {
+	// the head is optional on event stores
+	hs, ok := es.(core.HeadStore)
+	if !ok {
+		return nil
+	}
+	events := testEvents(AggregateID())
+	err := es.Save(events)
+	if err != nil {
+		return err
+	}
+	head, err := hs.Head(context.Background())
+	if err != nil {
+		return err
+	}
+	if head < events[len(events)-1].GlobalVersion {
+		return fmt.Errorf("expected head to be at least %d got %d", events[len(events)-1].GlobalVersion, head)
+	}
+	err = es.Save([]core.Event{testEventOtherAggregate(AggregateID())})
+	if err != nil {
+		return err
+	}
+	next, err := hs.Head(context.Background())
+	if err != nil {
+		return err
+	}
+	if next <= head {
+		return fmt.Errorf("expected head to move from %d got %d", head, next)
+	}
+	return nil
+}
+
+func saveStreams(es core.EventStore) error {
+	// saving several aggregates atomically is optional on event stores
+	ms, ok := es.(core.MultiStreamSaver)
+	if !ok {
+		return nil
+	}
+	aggregateID := AggregateID()
+	aggregateID2 := AggregateID()
+	events := testEvents(aggregateID)
+	events2 := []core.Event{testEventOtherAggregate(aggregateID2)}
+	err := ms.SaveStreams([][]core.Event{events, events2})
+	if err != nil {
+		return err
+	}
+	if events2[0].GlobalVersion <= events[len(events)-1].GlobalVersion {
+		return fmt.Errorf("expected larger global event order got %d", events2[0].GlobalVersion)
+	}
+
+	// the events of the other aggregate are not saved when one aggregate is in the wrong version
+	aggregateID3 := AggregateID()
+	err = ms.SaveStreams([][]core.Event{{testEventOtherAggregate(aggregateID3)}, testEventsPartTwo(aggregateID2)})
+	if !errors.Is(err, core.ErrConcurrency) {
+		return fmt.Errorf("expected concurrency error got %v", err)
+	}
+	iterator, err := es.Get(context.Background(), aggregateID3, aggregateType, 0)
+	if err != nil {
+		return err
+	}
+	defer iterator.Close()
+	if iterator.Next() {
+		return fmt.Errorf("expected no events saved on %s", aggregateID3)
+	}
+	return nil
+}
+
 /* re-activate when esdb eventstore have global event order on each stream
 func setGlobalVersionOnSavedEvents(es eventsourcing.EventStore) error {
 	events := testEvents()
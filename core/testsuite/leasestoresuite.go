@@ -0,0 +1,94 @@
+package testsuite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+type leasestoreFunc = func() (core.LeaseStore, func(), error)
+
+func TestLeaseStore(t *testing.T, lsFunc leasestoreFunc) {
+	tests := []struct {
+		title string
+		run   func(ls core.LeaseStore) error
+	}{
+		{"should acquire and renew lease", acquireAndRenewLease},
+		{"should get error when lease is held by other owner", acquireHeldLease},
+		{"should acquire released lease", acquireReleasedLease},
+		{"should acquire expired lease", acquireExpiredLease},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			ls, closeFunc, err := lsFunc()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = test.run(ls)
+			if err != nil {
+				// make use of t.Error instead of t.Fatal to make sure the closeFunc is executed
+				t.Error(err)
+			}
+			closeFunc()
+		})
+	}
+}
+
+func acquireAndRenewLease(ls core.LeaseStore) error {
+	name := "projection_" + AggregateID()
+	err := ls.Acquire(context.Background(), name, "a", time.Minute)
+	if err != nil {
+		return err
+	}
+	return ls.Acquire(context.Background(), name, "a", time.Minute)
+}
+
+func acquireHeldLease(ls core.LeaseStore) error {
+	name := "projection_" + AggregateID()
+	err := ls.Acquire(context.Background(), name, "a", time.Minute)
+	if err != nil {
+		return err
+	}
+	err = ls.Acquire(context.Background(), name, "b", time.Minute)
+	if !errors.Is(err, core.ErrLeaseHeld) {
+		return fmt.Errorf("expected core.ErrLeaseHeld got %v", err)
+	}
+	// release by other owner does not release the lease
+	err = ls.Release(name, "b")
+	if err != nil {
+		return err
+	}
+	err = ls.Acquire(context.Background(), name, "b", time.Minute)
+	if !errors.Is(err, core.ErrLeaseHeld) {
+		return fmt.Errorf("expected core.ErrLeaseHeld after release by other owner got %v", err)
+	}
+	return nil
+}
+
+func acquireReleasedLease(ls core.LeaseStore) error {
+	name := "projection_" + AggregateID()
+	err := ls.Acquire(context.Background(), name, "a", time.Minute)
+	if err != nil {
+		return err
+	}
+	err = ls.Release(name, "a")
+	if err != nil {
+		return err
+	}
+	return ls.Acquire(context.Background(), name, "b", time.Minute)
+}
+
+func acquireExpiredLease(ls core.LeaseStore) error {
+	name := "projection_" + AggregateID()
+	err := ls.Acquire(context.Background(), name, "a", time.Millisecond)
+	if err != nil {
+		return err
+	}
+	time.Sleep(time.Millisecond * 10)
+	return ls.Acquire(context.Background(), name, "b", time.Minute)
+}
@@ -0,0 +1,107 @@
+package testsuite
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+type memberstoreFunc = func() (core.MemberStore, func(), error)
+
+func TestMemberStore(t *testing.T, msFunc memberstoreFunc) {
+	tests := []struct {
+		title string
+		run   func(ms core.MemberStore) error
+	}{
+		{"should return live members sorted", heartbeatMembers},
+		{"should not return member that left", leaveMember},
+		{"should not return expired member", expiredMember},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			ms, closeFunc, err := msFunc()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = test.run(ms)
+			if err != nil {
+				// make use of t.Error instead of t.Fatal to make sure the closeFunc is executed
+				t.Error(err)
+			}
+			closeFunc()
+		})
+	}
+}
+
+func heartbeatMembers(ms core.MemberStore) error {
+	group := "group_" + AggregateID()
+	members, err := ms.Heartbeat(context.Background(), group, "b", time.Minute)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(members, []string{"b"}) {
+		return fmt.Errorf("expected members [b] got %v", members)
+	}
+	members, err = ms.Heartbeat(context.Background(), group, "a", time.Minute)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(members, []string{"a", "b"}) {
+		return fmt.Errorf("expected members [a b] got %v", members)
+	}
+	// members in other groups are not returned
+	_, err = ms.Heartbeat(context.Background(), "other_"+group, "c", time.Minute)
+	if err != nil {
+		return err
+	}
+	members, err = ms.Heartbeat(context.Background(), group, "b", time.Minute)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(members, []string{"a", "b"}) {
+		return fmt.Errorf("expected members [a b] got %v", members)
+	}
+	return nil
+}
+
+func leaveMember(ms core.MemberStore) error {
+	group := "group_" + AggregateID()
+	_, err := ms.Heartbeat(context.Background(), group, "a", time.Minute)
+	if err != nil {
+		return err
+	}
+	err = ms.Leave(group, "a")
+	if err != nil {
+		return err
+	}
+	members, err := ms.Heartbeat(context.Background(), group, "b", time.Minute)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(members, []string{"b"}) {
+		return fmt.Errorf("expected members [b] got %v", members)
+	}
+	return nil
+}
+
+func expiredMember(ms core.MemberStore) error {
+	group := "group_" + AggregateID()
+	_, err := ms.Heartbeat(context.Background(), group, "a", time.Millisecond)
+	if err != nil {
+		return err
+	}
+	time.Sleep(time.Millisecond * 10)
+	members, err := ms.Heartbeat(context.Background(), group, "b", time.Minute)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(members, []string{"b"}) {
+		return fmt.Errorf("expected members [b] got %v", members)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/internal"
+)
+
+// ErrNoDeadLetterStore is returned when dead letters are listed or replayed on a projection without a dead-letter store
+var ErrNoDeadLetterStore = errors.New("projection has no dead-letter store")
+
+// ErrTxDeadLetters is returned when dead letters are replayed on a transactional projection
+var ErrTxDeadLetters = errors.New("dead letters can't be replayed on a transactional projection")
+
+// ErrorPolicy decides what a projection does when the callback returns an error. The zero value
+// halts the projection on the first error.
+//
+// The policy is not applied on transactional projections as a failed statement could leave the
+// transaction in an aborted state.
+type ErrorPolicy struct {
+	Retries    int                  // Retries is the number of times the callback is retried before giving up on the event
+	Backoff    time.Duration        // Backoff is the wait before the first retry, it's doubled for each retry
+	MaxBackoff time.Duration        // MaxBackoff caps the wait between retries
+	DeadLetter core.DeadLetterStore // DeadLetter records and skips the event when the retries are exhausted, if nil the projection halts
+}
+
+// handle calls the callback and applies the error policy if it fails
+func (p *Projection) handle(ctx context.Context, event Event) error {
+	if p.transactor != nil {
+		err := p.begin(ctx)
+		if err != nil {
+			return err
+		}
+		return p.call(ctx, event)
+	}
+
+	attempts, err := p.retry(ctx, func(ctx context.Context) error {
+		return p.call(ctx, event)
+	})
+	if err == nil {
+		return nil
+	}
+	// a dry run reports the error instead of storing a dead letter
+	if p.OnError.DeadLetter == nil || p.DryRun {
+		return err
+	}
+	return p.deadLetter(event, err, attempts)
+}
+
+// deadLetter records the event that failed after the attempts in the dead-letter store
+func (p *Projection) deadLetter(event Event, err error, attempts int) error {
+	return p.OnError.DeadLetter.Save(core.DeadLetter{
+		Name:      p.Name,
+		Event:     event.event,
+		Error:     err.Error(),
+		Attempts:  attempts,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// retry calls f until it succeeds or the retries in the error policy are exhausted, it returns the number of attempts
+func (p *Projection) retry(ctx context.Context, f func(ctx context.Context) error) (int, error) {
+	attempts := 0
+	for {
+		attempts++
+		err := f(ctx)
+		if err == nil || attempts > p.OnError.Retries {
+			return attempts, err
+		}
+		select {
+		case <-ctx.Done():
+			return attempts, ctx.Err()
+		case <-time.After(internal.Backoff(p.OnError.Backoff, p.OnError.MaxBackoff, attempts-1)):
+		}
+	}
+}
+
+// call calls the callback with a context that has a deadline if EventTimeout is set
+func (p *Projection) call(ctx context.Context, event Event) error {
+	ctx, cancel := p.eventContext(ctx)
+	defer cancel()
+	if p.batchF != nil {
+		return p.batchF(ctx, []Event{event})
+	}
+	return p.callbackF(ctx, event)
+}
+
+// eventContext returns a context with a deadline if EventTimeout is set
+func (p *Projection) eventContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.EventTimeout > 0 {
+		return context.WithTimeout(ctx, p.EventTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// DeadLetters returns the events the projection has dead-lettered
+func (p *Projection) DeadLetters(ctx context.Context) ([]core.DeadLetter, error) {
+	if p.OnError.DeadLetter == nil {
+		return nil, ErrNoDeadLetterStore
+	}
+	return p.OnError.DeadLetter.List(ctx, p.Name)
+}
+
+// ReplayDeadLetters calls the callback with the dead-lettered events in global version order. Events that are
+// handled are removed from the dead-letter store, the ones that fail again are stored with an increased attempt count.
+// It returns the number of replayed events that were handled successfully.
+//
+// It can be called on a running projection, the replay waits for the fetched events to be handled and the projection
+// waits for the replay to finish. Transactional projections don't dead-letter events and return ErrTxDeadLetters.
+func (p *Projection) ReplayDeadLetters(ctx context.Context) (int, error) {
+	if p.transactor != nil {
+		return 0, ErrTxDeadLetters
+	}
+	p.exec.Lock()
+	defer p.exec.Unlock()
+
+	deadLetters, err := p.DeadLetters(ctx)
+	if err != nil {
+		return 0, err
+	}
+	handled := 0
+	for _, deadLetter := range deadLetters {
+		if ctx.Err() != nil {
+			return handled, ctx.Err()
+		}
+		event, err := toEvent(deadLetter.Event)
+		if err != nil {
+			return handled, err
+		}
+		err = p.call(ctx, event)
+		if err != nil {
+			deadLetter.Attempts++
+			deadLetter.Error = err.Error()
+			deadLetter.Timestamp = time.Now().UTC()
+			err = p.OnError.DeadLetter.Save(deadLetter)
+			if err != nil {
+				return handled, err
+			}
+			continue
+		}
+		err = p.OnError.DeadLetter.Delete(deadLetter.Name, deadLetter.Event.GlobalVersion)
+		if err != nil {
+			return handled, err
+		}
+		handled++
+	}
+	return handled, nil
+}
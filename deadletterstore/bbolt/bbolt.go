@@ -0,0 +1,116 @@
+package bbolt
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+const deadLetterBucketName = "dead_letters"
+
+// BBolt is the dead-letter store handler
+type BBolt struct {
+	db *bbolt.DB
+}
+
+// New binds the dead-letter store to the bbolt database. The database can be shared with the bbolt
+// event store and checkpoint store. The dead letters of a projection are stored in a bucket on its name
+// keyed on the global version of the event.
+func New(db *bbolt.DB) (*BBolt, error) {
+	err := db.Update(func(tx *bbolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists([]byte(deadLetterBucketName)); err != nil {
+			return errors.New("could not create dead-letter bucket")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &BBolt{
+		db: db,
+	}, nil
+}
+
+// Close closes the underlying database
+func (b *BBolt) Close() error {
+	return b.db.Close()
+}
+
+// Save stores the dead letter, an existing dead letter for the same event is replaced
+func (b *BBolt) Save(deadLetter core.DeadLetter) error {
+	value, err := json.Marshal(deadLetter)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(deadLetterBucketName))
+		if bucket == nil {
+			return errors.New("dead-letter bucket not found")
+		}
+		projection, err := bucket.CreateBucketIfNotExists([]byte(deadLetter.Name))
+		if err != nil {
+			return fmt.Errorf("could not create dead-letter bucket for %s, %v", deadLetter.Name, err)
+		}
+		if err := projection.Put(key(deadLetter.Event.GlobalVersion), value); err != nil {
+			return fmt.Errorf("could not save dead letter for %s, %v", deadLetter.Name, err)
+		}
+		return nil
+	})
+}
+
+// List returns the dead letters of the projection in global version order
+func (b *BBolt) List(ctx context.Context, name string) ([]core.DeadLetter, error) {
+	deadLetters := make([]core.DeadLetter, 0)
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(deadLetterBucketName))
+		if bucket == nil {
+			return errors.New("dead-letter bucket not found")
+		}
+		projection := bucket.Bucket([]byte(name))
+		if projection == nil {
+			return nil
+		}
+		// the keys are big endian global versions and iterated in global version order
+		return projection.ForEach(func(_, value []byte) error {
+			var deadLetter core.DeadLetter
+			err := json.Unmarshal(value, &deadLetter)
+			if err != nil {
+				return err
+			}
+			deadLetters = append(deadLetters, deadLetter)
+			return ctx.Err()
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deadLetters, nil
+}
+
+// Delete removes the dead letter
+func (b *BBolt) Delete(name string, globalVersion core.Version) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(deadLetterBucketName))
+		if bucket == nil {
+			return errors.New("dead-letter bucket not found")
+		}
+		projection := bucket.Bucket([]byte(name))
+		if projection == nil {
+			return nil
+		}
+		return projection.Delete(key(globalVersion))
+	})
+}
+
+// key returns the global version as a big endian key
+func key(globalVersion core.Version) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(globalVersion))
+	return b
+}
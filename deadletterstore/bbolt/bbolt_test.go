@@ -0,0 +1,32 @@
+package bbolt_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/core/testsuite"
+	ds "github.com/r23vme/eventsourcing/deadletterstore/bbolt"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (core.DeadLetterStore, func(), error) {
+		dbFile := "deadletter.db"
+		db, err := bbolt.Open(dbFile, 0600, &bbolt.Options{Timeout: time.Second})
+		if err != nil {
+			return nil, nil, err
+		}
+		store, err := ds.New(db)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() {
+			store.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	testsuite.TestDeadLetterStore(t, f)
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+type Memory struct {
+	deadLetters map[string]map[core.Version]core.DeadLetter
+	lock        sync.Mutex
+}
+
+// Create in memory dead-letter store
+func Create() *Memory {
+	return &Memory{
+		deadLetters: make(map[string]map[core.Version]core.DeadLetter),
+	}
+}
+
+func (m *Memory) Close() {
+
+}
+
+// Save stores the dead letter, an existing dead letter for the same event is replaced
+func (m *Memory) Save(deadLetter core.DeadLetter) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	deadLetters, ok := m.deadLetters[deadLetter.Name]
+	if !ok {
+		deadLetters = make(map[core.Version]core.DeadLetter)
+		m.deadLetters[deadLetter.Name] = deadLetters
+	}
+	deadLetters[deadLetter.Event.GlobalVersion] = deadLetter
+	return nil
+}
+
+// List returns the dead letters of the projection in global version order
+func (m *Memory) List(ctx context.Context, name string) ([]core.DeadLetter, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	deadLetters := make([]core.DeadLetter, 0, len(m.deadLetters[name]))
+	for _, deadLetter := range m.deadLetters[name] {
+		deadLetters = append(deadLetters, deadLetter)
+	}
+	sort.Slice(deadLetters, func(i, j int) bool {
+		return deadLetters[i].Event.GlobalVersion < deadLetters[j].Event.GlobalVersion
+	})
+	return deadLetters, ctx.Err()
+}
+
+// Delete removes the dead letter
+func (m *Memory) Delete(name string, globalVersion core.Version) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.deadLetters[name], globalVersion)
+	return nil
+}
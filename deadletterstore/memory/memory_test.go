@@ -0,0 +1,17 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/core/testsuite"
+	"github.com/r23vme/eventsourcing/deadletterstore/memory"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (core.DeadLetterStore, func(), error) {
+		ds := memory.Create()
+		return ds, func() { ds.Close() }, nil
+	}
+	testsuite.TestDeadLetterStore(t, f)
+}
@@ -0,0 +1,75 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+const createTablePostgres = `CREATE TABLE IF NOT EXISTS dead_letters (
+    name VARCHAR NOT NULL,
+    global_version BIGINT NOT NULL,
+    id VARCHAR,
+    version BIGINT,
+    type VARCHAR,
+    reason VARCHAR,
+    event_timestamp VARCHAR,
+    data BYTEA,
+    metadata BYTEA,
+    error VARCHAR,
+    attempts INTEGER,
+    timestamp VARCHAR,
+    PRIMARY KEY (name, global_version)
+);`
+
+type Postgres struct {
+	db *sql.DB
+}
+
+// NewPostgres connection to database
+func NewPostgres(db *sql.DB) (*Postgres, error) {
+	if err := migrate(db, []string{
+		createTablePostgres,
+	}); err != nil {
+		return nil, err
+	}
+	return &Postgres{
+		db: db,
+	}, nil
+}
+
+// Close the connection
+func (s *Postgres) Close() {
+	s.db.Close()
+}
+
+// Save stores the dead letter, an existing dead letter for the same event is replaced
+func (s *Postgres) Save(deadLetter core.DeadLetter) error {
+	statement := `INSERT INTO dead_letters (name, global_version, id, version, type, reason, event_timestamp, data, metadata, error, attempts, timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (name, global_version) DO UPDATE SET error=EXCLUDED.error, attempts=EXCLUDED.attempts, timestamp=EXCLUDED.timestamp`
+	event := deadLetter.Event
+	_, err := s.db.Exec(statement, deadLetter.Name, int64(event.GlobalVersion), event.AggregateID, int64(event.Version), event.AggregateType,
+		event.Reason, event.Timestamp.Format(time.RFC3339Nano), event.Data, event.Metadata, deadLetter.Error, deadLetter.Attempts,
+		deadLetter.Timestamp.Format(time.RFC3339Nano))
+	return err
+}
+
+// List returns the dead letters of the projection in global version order
+func (s *Postgres) List(ctx context.Context, name string) ([]core.DeadLetter, error) {
+	selectStm := `SELECT name, global_version, id, version, type, reason, event_timestamp, data, metadata, error, attempts, timestamp
+FROM dead_letters WHERE name=$1 ORDER BY global_version ASC`
+	rows, err := s.db.QueryContext(ctx, selectStm, name)
+	if err != nil {
+		return nil, err
+	}
+	return scanDeadLetters(rows)
+}
+
+// Delete removes the dead letter
+func (s *Postgres) Delete(name string, globalVersion core.Version) error {
+	_, err := s.db.Exec(`DELETE FROM dead_letters WHERE name=$1 AND global_version=$2`, name, int64(globalVersion))
+	return err
+}
@@ -0,0 +1,39 @@
+package sql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+// scanDeadLetters reads the dead letters from the rows selected in column order
+// name, global_version, id, version, type, reason, event_timestamp, data, metadata, error, attempts, timestamp
+func scanDeadLetters(rows *sql.Rows) ([]core.DeadLetter, error) {
+	defer rows.Close()
+
+	deadLetters := make([]core.DeadLetter, 0)
+	for rows.Next() {
+		var deadLetter core.DeadLetter
+		var globalVersion, version int64
+		var eventTimestamp, timestamp string
+		err := rows.Scan(&deadLetter.Name, &globalVersion, &deadLetter.Event.AggregateID, &version, &deadLetter.Event.AggregateType,
+			&deadLetter.Event.Reason, &eventTimestamp, &deadLetter.Event.Data, &deadLetter.Event.Metadata, &deadLetter.Error,
+			&deadLetter.Attempts, &timestamp)
+		if err != nil {
+			return nil, err
+		}
+		deadLetter.Event.GlobalVersion = core.Version(globalVersion)
+		deadLetter.Event.Version = core.Version(version)
+		deadLetter.Event.Timestamp, err = time.Parse(time.RFC3339Nano, eventTimestamp)
+		if err != nil {
+			return nil, err
+		}
+		deadLetter.Timestamp, err = time.Parse(time.RFC3339Nano, timestamp)
+		if err != nil {
+			return nil, err
+		}
+		deadLetters = append(deadLetters, deadLetter)
+	}
+	return deadLetters, rows.Err()
+}
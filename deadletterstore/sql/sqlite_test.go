@@ -0,0 +1,40 @@
+package sql_test
+
+import (
+	sqldriver "database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/core/testsuite"
+	"github.com/r23vme/eventsourcing/deadletterstore/sql"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (core.DeadLetterStore, func(), error) {
+		return deadletterstore()
+	}
+	testsuite.TestDeadLetterStore(t, f)
+}
+
+func deadletterstore() (*sql.SQLite, func(), error) {
+	db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+	err = db.Ping()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store, err := sql.NewSQLite(db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return store, func() {
+		store.Close()
+	}, nil
+}
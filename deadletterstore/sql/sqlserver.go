@@ -0,0 +1,93 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+const createTableSQLServer = `IF OBJECT_ID('[dead_letters]', 'U') IS NULL
+BEGIN
+    CREATE TABLE [dead_letters] (
+        [name] NVARCHAR(255) NOT NULL,
+        [global_version] BIGINT NOT NULL,
+        [id] NVARCHAR(255),
+        [version] BIGINT,
+        [type] NVARCHAR(255),
+        [reason] NVARCHAR(255),
+        [event_timestamp] NVARCHAR(255),
+        [data] VARBINARY(MAX),
+        [metadata] VARBINARY(MAX),
+        [error] NVARCHAR(MAX),
+        [attempts] INT,
+        [timestamp] NVARCHAR(255),
+        CONSTRAINT pk_dead_letters PRIMARY KEY ([name], [global_version])
+    );
+END`
+
+type SQLServer struct {
+	db *sql.DB
+}
+
+// NewSQLServer connection to database
+func NewSQLServer(db *sql.DB) (*SQLServer, error) {
+	if err := migrate(db, []string{
+		createTableSQLServer,
+	}); err != nil {
+		return nil, err
+	}
+	return &SQLServer{
+		db: db,
+	}, nil
+}
+
+// Close the connection
+func (s *SQLServer) Close() {
+	s.db.Close()
+}
+
+// Save stores the dead letter, an existing dead letter for the same event is replaced
+func (s *SQLServer) Save(deadLetter core.DeadLetter) error {
+	statement := `MERGE [dead_letters] WITH (HOLDLOCK) AS target
+USING (SELECT @name AS [name], @global_version AS [global_version]) AS source
+ON target.[name] = source.[name] AND target.[global_version] = source.[global_version]
+WHEN MATCHED THEN UPDATE SET [error] = @error, [attempts] = @attempts, [timestamp] = @timestamp
+WHEN NOT MATCHED THEN INSERT ([name], [global_version], [id], [version], [type], [reason], [event_timestamp], [data], [metadata], [error], [attempts], [timestamp])
+VALUES (@name, @global_version, @id, @version, @type, @reason, @event_timestamp, @data, @metadata, @error, @attempts, @timestamp);`
+	event := deadLetter.Event
+	_, err := s.db.Exec(statement,
+		sql.Named("name", deadLetter.Name),
+		sql.Named("global_version", int64(event.GlobalVersion)),
+		sql.Named("id", event.AggregateID),
+		sql.Named("version", int64(event.Version)),
+		sql.Named("type", event.AggregateType),
+		sql.Named("reason", event.Reason),
+		sql.Named("event_timestamp", event.Timestamp.Format(time.RFC3339Nano)),
+		sql.Named("data", event.Data),
+		sql.Named("metadata", event.Metadata),
+		sql.Named("error", deadLetter.Error),
+		sql.Named("attempts", deadLetter.Attempts),
+		sql.Named("timestamp", deadLetter.Timestamp.Format(time.RFC3339Nano)),
+	)
+	return err
+}
+
+// List returns the dead letters of the projection in global version order
+func (s *SQLServer) List(ctx context.Context, name string) ([]core.DeadLetter, error) {
+	selectStm := `SELECT [name], [global_version], [id], [version], [type], [reason], [event_timestamp], [data], [metadata], [error], [attempts], [timestamp]
+FROM [dead_letters] WHERE [name] = @name ORDER BY [global_version] ASC;`
+	rows, err := s.db.QueryContext(ctx, selectStm, sql.Named("name", name))
+	if err != nil {
+		return nil, err
+	}
+	return scanDeadLetters(rows)
+}
+
+// Delete removes the dead letter
+func (s *SQLServer) Delete(name string, globalVersion core.Version) error {
+	_, err := s.db.Exec(`DELETE FROM [dead_letters] WHERE [name] = @name AND [global_version] = @global_version;`,
+		sql.Named("name", name), sql.Named("global_version", int64(globalVersion)))
+	return err
+}
@@ -0,0 +1,69 @@
+package sql_test
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	_ "github.com/denisenkom/go-mssqldb"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/core/testsuite"
+	"github.com/r23vme/eventsourcing/deadletterstore/sql"
+)
+
+func TestSuiteSQLServer(t *testing.T) {
+	ctx := context.Background()
+
+	// Start MSSQL container
+	req := testcontainers.ContainerRequest{
+		Image:        "mcr.microsoft.com/mssql/server:2019-latest",
+		ExposedPorts: []string{"1433/tcp"},
+		Env: map[string]string{
+			"ACCEPT_EULA": "Y",
+			"SA_PASSWORD": "YourStrong(!)Password",
+		},
+		WaitingFor: wait.ForLog("SQL Server is now ready for client connections").WithStartupTimeout(2 * time.Minute),
+	}
+
+	mssqlC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		t.Fatalf("failed to start container: %v", err)
+	}
+	defer mssqlC.Terminate(ctx)
+
+	host, _ := mssqlC.Host(ctx)
+	port, _ := mssqlC.MappedPort(ctx, "1433")
+
+	dsn := fmt.Sprintf("sqlserver://sa:YourStrong(!)Password@%s:%s?database=master", host, port.Port())
+
+	f := func() (core.DeadLetterStore, func(), error) {
+		var db *gosql.DB
+		var err error
+		for i := 0; i < 10; i++ {
+			db, err = gosql.Open("sqlserver", dsn)
+			if err == nil && db.Ping() == nil {
+				break
+			}
+			time.Sleep(2 * time.Second)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		ds, err := sql.NewSQLServer(db)
+		if err != nil {
+			return nil, nil, err
+		}
+		return ds, func() {
+			db.Close()
+		}, nil
+	}
+	testsuite.TestDeadLetterStore(t, f)
+}
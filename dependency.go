@@ -0,0 +1,165 @@
+package eventsourcing
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+// ErrDependencyCycle is returned when a dependency would make a projection depend on itself
+var ErrDependencyCycle = errors.New("projection dependency cycle")
+
+// dependencyGraph keeps the upstream projections of the projections in a group
+type dependencyGraph struct {
+	lock        sync.RWMutex
+	upstreams   map[string][]string    // downstream projection name -> upstream projection names
+	projections map[string]*Projection // projections started in the group by name
+}
+
+func newDependencyGraph() *dependencyGraph {
+	return &dependencyGraph{
+		upstreams:   make(map[string][]string),
+		projections: make(map[string]*Projection),
+	}
+}
+
+// DependsOn declares that the projection with the name depends on the upstream projections. The projection does
+// not handle events beyond the global version all its upstream projections have handled, and is triggered when
+// they advance. An upstream projection that is not in the group holds the projection back.
+//
+// Only checkpoint projections can be held back. The position of an upstream projection is the global version of
+// the last event it handled, or of the last event skipped by its fetcher when it has handled all fetched events.
+func (g *ProjectionGroup) DependsOn(name string, upstreams ...string) error {
+	g.graph.lock.Lock()
+	defer g.graph.lock.Unlock()
+	for _, upstream := range upstreams {
+		if upstream == name || g.graph.reaches(upstream, name) {
+			return fmt.Errorf("%s depends on %s: %w", name, upstream, ErrDependencyCycle)
+		}
+	}
+	for _, upstream := range upstreams {
+		if !slices.Contains(g.graph.upstreams[name], upstream) {
+			g.graph.upstreams[name] = append(g.graph.upstreams[name], upstream)
+		}
+	}
+	// the projection could be held back by the new upstream projections
+	if p := g.graph.projections[name]; p != nil {
+		p.TriggerAsync()
+	}
+	return nil
+}
+
+// reaches returns true if the projection depends on the upstream projection directly or indirectly
+func (d *dependencyGraph) reaches(name, upstream string) bool {
+	for _, u := range d.upstreams[name] {
+		if u == upstream || d.reaches(u, upstream) {
+			return true
+		}
+	}
+	return false
+}
+
+// add makes the projection a possible upstream projection
+func (d *dependencyGraph) add(p *Projection) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.projections[p.Name] = p
+}
+
+// remove removes the projection so it holds back its downstream projections
+func (d *dependencyGraph) remove(p *Projection) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.projections[p.Name] == p {
+		delete(d.projections, p.Name)
+	}
+}
+
+// ceiling returns the lowest position of the upstream projections and true if the projection has upstream
+// projections
+func (d *dependencyGraph) ceiling(name string) (core.Version, bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	upstreams := d.upstreams[name]
+	if len(upstreams) == 0 {
+		return 0, false
+	}
+	var ceiling core.Version
+	for i, upstream := range upstreams {
+		var position core.Version
+		if p := d.projections[upstream]; p != nil {
+			position = core.Version(p.position.Load())
+		}
+		if i == 0 || position < ceiling {
+			ceiling = position
+		}
+	}
+	return ceiling, true
+}
+
+// advanced triggers the projections that depend on the projection
+func (d *dependencyGraph) advanced(p *Projection) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	for name, upstreams := range d.upstreams {
+		if !slices.Contains(upstreams, p.Name) {
+			continue
+		}
+		if downstream := d.projections[name]; downstream != nil {
+			downstream.TriggerAsync()
+		}
+	}
+}
+
+// ceiling returns the global version the projection is not allowed to pass and true if it's held back by
+// upstream projections
+func (p *Projection) ceiling() (core.Version, bool) {
+	graph := p.graph.Load()
+	if graph == nil || p.fetchFrom == nil {
+		return 0, false
+	}
+	return graph.ceiling(p.Name)
+}
+
+// boundIterator stops before the first event after the ceiling and keeps its global version
+type boundIterator struct {
+	iterator core.Iterator
+	ceiling  core.Version
+	held     core.Version // global version of the first event after the ceiling
+	event    core.Event
+	err      error
+	position core.Version
+}
+
+func (i *boundIterator) Next() bool {
+	if i.held != 0 {
+		return false
+	}
+	if !i.iterator.Next() {
+		i.position = core.IteratorPosition(i.iterator, i.position)
+		return false
+	}
+	i.event, i.err = i.iterator.Value()
+	if i.err == nil && i.event.GlobalVersion > i.ceiling {
+		i.held = i.event.GlobalVersion
+		return false
+	}
+	i.position = core.IteratorPosition(i.iterator, i.event.GlobalVersion)
+	return true
+}
+
+func (i *boundIterator) Value() (core.Event, error) {
+	return i.event, i.err
+}
+
+// Position returns the global version of the last event before the ceiling
+func (i *boundIterator) Position() core.Version {
+	return i.position
+}
+
+func (i *boundIterator) Close() {
+	i.iterator.Close()
+}
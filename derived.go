@@ -0,0 +1,184 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/internal"
+)
+
+// SourceGlobalVersion is the metadata key on a derived event holding the global version of the event it was
+// derived from
+const SourceGlobalVersion = "source_global_version"
+
+// EmitFunc emits a derived event with the data to the stream of the aggregate type and id
+type EmitFunc func(aggregateType, aggregateID string, data interface{}) error
+
+// stream identifies an aggregate event stream
+type stream struct {
+	aggregateType string
+	aggregateID   string
+}
+
+// streamPosition is the version of the last event in a derived stream and the global version of the event it
+// was derived from
+type streamPosition struct {
+	version core.Version
+	source  core.Version
+}
+
+// emitter saves the derived events to the event store
+type emitter struct {
+	es        core.EventStore
+	lock      sync.Mutex
+	positions map[stream]streamPosition
+}
+
+// NewDerivedProjection creates a checkpoint projection where derive emits new events derived from the handled
+// events. The events emitted from an event are saved to the event store after derive returns, one save per stream.
+// Each derived event has the global version of the event it was derived from in its metadata, events that are
+// handled again, after a crash or when the projection is moved back, are not emitted to the streams that already
+// have events derived from them.
+//
+// The data of the derived events has to be registered on an aggregate with the aggregate type of the stream.
+func NewDerivedProjection(name string, cs core.CheckpointStore, fetchFrom core.FetcherFrom, es core.EventStore, derive func(ctx context.Context, event Event, emit EmitFunc) error) *Projection {
+	e := &emitter{
+		es:        es,
+		positions: make(map[stream]streamPosition),
+	}
+	return NewCheckpointProjectionContext(name, cs, fetchFrom, func(ctx context.Context, event Event) error {
+		var streams []stream
+		emitted := make(map[stream][]core.Event)
+		err := derive(ctx, event, func(aggregateType, aggregateID string, data interface{}) error {
+			s := stream{aggregateType: aggregateType, aggregateID: aggregateID}
+			esEvent, err := derivedEvent(s, event, data)
+			if err != nil {
+				return err
+			}
+			if _, ok := emitted[s]; !ok {
+				streams = append(streams, s)
+			}
+			emitted[s] = append(emitted[s], esEvent)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, s := range streams {
+			err = e.save(ctx, s, core.Version(event.GlobalVersion()), emitted[s])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// derivedEvent serializes the derived event data
+func derivedEvent(s stream, source Event, data interface{}) (core.Event, error) {
+	if reflect.ValueOf(data).Kind() != reflect.Ptr {
+		return core.Event{}, fmt.Errorf("derived event %T needs to be a pointer", data)
+	}
+	esEvent := core.Event{
+		AggregateID:   s.aggregateID,
+		AggregateType: s.aggregateType,
+		Reason:        reflect.TypeOf(data).Elem().Name(),
+		Timestamp:     time.Now().UTC(),
+	}
+	_, ok := internal.GlobalRegister.EventRegistered(esEvent)
+	if !ok {
+		return core.Event{}, fmt.Errorf("%s %w", esEvent.Reason, ErrEventNotRegistered)
+	}
+	var err error
+	esEvent.Data, err = internal.EventEncoder.Serialize(data)
+	if err != nil {
+		return core.Event{}, err
+	}
+	esEvent.Metadata, err = internal.EventEncoder.Serialize(map[string]interface{}{SourceGlobalVersion: source.GlobalVersion()})
+	if err != nil {
+		return core.Event{}, err
+	}
+	return esEvent, nil
+}
+
+// save appends the events derived from the source event to the stream unless the stream already has events
+// derived from the source event or a later event. On a concurrency error the stream position is loaded again.
+func (e *emitter) save(ctx context.Context, s stream, source core.Version, events []core.Event) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	position, ok := e.positions[s]
+	for attempt := 0; ; attempt++ {
+		if !ok {
+			var err error
+			position, err = e.load(ctx, s)
+			if err != nil {
+				return err
+			}
+			e.positions[s] = position
+		}
+		if position.source >= source {
+			return nil
+		}
+		for i := range events {
+			events[i].Version = position.version + core.Version(i) + 1
+		}
+		err := e.es.Save(events)
+		if errors.Is(err, core.ErrConcurrency) && attempt == 0 {
+			ok = false
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		e.positions[s] = streamPosition{version: events[len(events)-1].Version, source: source}
+		return nil
+	}
+}
+
+// load reads the stream to find the version of its last event and the global version it was derived from
+func (e *emitter) load(ctx context.Context, s stream) (streamPosition, error) {
+	iterator, err := e.es.Get(ctx, s.aggregateID, s.aggregateType, 0)
+	if err != nil {
+		return streamPosition{}, err
+	}
+	defer iterator.Close()
+
+	var position streamPosition
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil {
+			return streamPosition{}, err
+		}
+		position.version = event.Version
+		source, err := sourceGlobalVersion(event.Metadata)
+		if err != nil {
+			return streamPosition{}, err
+		}
+		if source > position.source {
+			position.source = source
+		}
+	}
+	return position, nil
+}
+
+// sourceGlobalVersion returns the global version of the source event from the metadata of a derived event, 0 if
+// the event is not derived. It's decoded straight into the version to keep the precision of large global versions.
+func sourceGlobalVersion(data []byte) (core.Version, error) {
+	if len(data) == 0 {
+		return 0, nil
+	}
+	var metadata struct {
+		SourceGlobalVersion core.Version `json:"source_global_version"`
+	}
+	err := internal.EventEncoder.Deserialize(data, &metadata)
+	if err != nil {
+		return 0, err
+	}
+	return metadata.SourceGlobalVersion, nil
+}
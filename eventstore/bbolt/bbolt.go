@@ -64,22 +64,42 @@ func (e *BBolt) Save(events []core.Event) error {
 	if len(events) == 0 {
 		return nil
 	}
+	return e.SaveStreams([][]core.Event{events})
+}
 
-	// get bucket name from first event
-	aggregateType := events[0].AggregateType
-	aggregateID := events[0].AggregateID
-	bucketRef := bucketRef(aggregateType, aggregateID)
-
+// SaveStreams saves the events of several aggregates in one transaction, if the events of one aggregate can't be
+// saved no events are saved
+func (e *BBolt) SaveStreams(streams [][]core.Event) error {
 	tx, err := e.db.Begin(true)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	for _, events := range streams {
+		err = e.saveEvents(tx, events)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// saveEvents saves the events of an aggregate in the transaction
+func (e *BBolt) saveEvents(tx *bbolt.Tx, events []core.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	// get bucket name from first event
+	aggregateType := events[0].AggregateType
+	aggregateID := events[0].AggregateID
+	bucketRef := bucketRef(aggregateType, aggregateID)
+
 	evBucket := tx.Bucket(bucketRef)
 	if evBucket == nil {
 		// Ensure that we have a bucket named events_aggregateType_aggregateID for the given aggregate
-		err = e.createBucket(bucketRef, tx)
+		err := e.createBucket(bucketRef, tx)
 		if err != nil {
 			return errors.New("could not create aggregate events bucket")
 		}
@@ -152,7 +172,7 @@ func (e *BBolt) Save(events []core.Event) error {
 		// override the event in the slice exposing the GlobalVersion to the caller
 		events[i].GlobalVersion = core.Version(globalSequence)
 	}
-	return tx.Commit()
+	return nil
 }
 
 // Get aggregate events
@@ -169,7 +189,7 @@ func (e *BBolt) Get(ctx context.Context, id string, aggregateType string, afterV
 	return &Iterator{tx: tx, cursor: cursor, startPosition: position(afterVersion)}, nil
 }
 
-// All iterate over event in GlobalEvents order
+// All iterate over event in GlobalEvents order starting on the event with the start global version
 func (e *BBolt) All(start core.Version) core.Fetcher {
 	iter := Iterator{}
 	return func() (core.Iterator, error) {
@@ -184,18 +204,44 @@ func (e *BBolt) All(start core.Version) core.Fetcher {
 		}
 		// set start from second call and forward
 		if iter.CurrentGlobalVersion != 0 {
-			// dont add 1 to CurrentGlobalVersion as the index is zero based
-			start = iter.CurrentGlobalVersion
+			start = iter.CurrentGlobalVersion + 1
 		}
 		cursor := bucket.Cursor()
 		iter.tx = tx
 		iter.cursor = cursor
-		iter.startPosition = position(core.Version(start))
+		iter.value = nil
+		// the global bucket keys are the global versions, start on the key equal to start
+		iter.startPosition = itob(uint64(start))
 		return &iter, nil
-		// return &iter{tx: tx, cursor: cursor, startPosition: position(core.Version(start))}, nil
 	}
 }
 
+// Head returns the global version of the latest saved event, 0 if there are no events
+func (e *BBolt) Head(ctx context.Context) (core.Version, error) {
+	var head core.Version
+	err := e.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(globalEventOrderBucketName))
+		if bucket == nil {
+			return nil
+		}
+		key, _ := bucket.Cursor().Last()
+		if key != nil {
+			head = core.Version(binary.BigEndian.Uint64(key))
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return head, ctx.Err()
+}
+
+// DB returns the underlying database, it can be used to store read models and checkpoints
+// in the same file as the events
+func (e *BBolt) DB() *bbolt.DB {
+	return e.db
+}
+
 // Close closes the event stream and the underlying database
 func (e *BBolt) Close() error {
 	return e.db.Close()
@@ -37,3 +37,42 @@ func TestFetchFuncAll(t *testing.T) {
 
 	testsuite.TestFetcher(t, es, es.All(0))
 }
+
+func TestFetchFuncAllFromStart(t *testing.T) {
+	dbFile := "bolt.db"
+	es, err := bbolt.New(dbFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		es.Close()
+		os.Remove(dbFile)
+	}()
+
+	events := []core.Event{
+		{AggregateID: "123", Version: 1, AggregateType: "person", Reason: "Born"},
+		{AggregateID: "123", Version: 2, AggregateType: "person", Reason: "AgedOneYear"},
+		{AggregateID: "123", Version: 3, AggregateType: "person", Reason: "AgedOneYear"},
+	}
+	err = es.Save(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iter, err := es.All(2)()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iter.Close()
+
+	if !iter.Next() {
+		t.Fatal("expected an event")
+	}
+	event, err := iter.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.GlobalVersion != 2 {
+		t.Fatalf("expected first event to have global version 2 was %d", event.GlobalVersion)
+	}
+}
@@ -28,40 +28,54 @@ func (e *Memory) Save(events []core.Event) error {
 	if len(events) == 0 {
 		return nil
 	}
+	return e.SaveStreams([][]core.Event{events})
+}
 
+// SaveStreams saves the events of several aggregates, if the events of one aggregate can't be saved no events
+// are saved
+func (e *Memory) SaveStreams(streams [][]core.Event) error {
 	// make sure its thread safe
 	e.lock.Lock()
 	defer e.lock.Unlock()
 
-	// get bucket name from first event
-	aggregateType := events[0].AggregateType
-	aggregateID := events[0].AggregateID
-	bucketName := aggregateKey(aggregateType, aggregateID)
-
-	evBucket := e.aggregateEvents[bucketName]
-	currentVersion := core.Version(0)
-
-	if len(evBucket) > 0 {
-		// Last version in the list
-		lastEvent := evBucket[len(evBucket)-1]
-		currentVersion = lastEvent.Version
-	}
-
-	// Make sure no other has saved event to the same aggregate concurrently
-	if core.Version(currentVersion)+1 != events[0].Version {
-		return core.ErrConcurrency
+	// Make sure no other has saved event to the aggregates concurrently before any event is saved
+	versions := make(map[string]core.Version)
+	for _, events := range streams {
+		if len(events) == 0 {
+			continue
+		}
+		bucketName := aggregateKey(events[0].AggregateType, events[0].AggregateID)
+		currentVersion, ok := versions[bucketName]
+		if !ok {
+			evBucket := e.aggregateEvents[bucketName]
+			if len(evBucket) > 0 {
+				// Last version in the list
+				currentVersion = evBucket[len(evBucket)-1].Version
+			}
+		}
+		if currentVersion+1 != events[0].Version {
+			return core.ErrConcurrency
+		}
+		versions[bucketName] = events[len(events)-1].Version
 	}
 
-	for i, event := range events {
-		// set the global version on the event +1 as if the event was already on the eventsInOrder slice
-		event.GlobalVersion = core.Version(len(e.eventsInOrder) + 1)
-		evBucket = append(evBucket, event)
-		e.eventsInOrder = append(e.eventsInOrder, event)
-		// override the event in the slice exposing the GlobalVersion to the caller
-		events[i].GlobalVersion = event.GlobalVersion
+	for _, events := range streams {
+		if len(events) == 0 {
+			continue
+		}
+		// get bucket name from first event
+		bucketName := aggregateKey(events[0].AggregateType, events[0].AggregateID)
+		evBucket := e.aggregateEvents[bucketName]
+		for i, event := range events {
+			// set the global version on the event +1 as if the event was already on the eventsInOrder slice
+			event.GlobalVersion = core.Version(len(e.eventsInOrder) + 1)
+			evBucket = append(evBucket, event)
+			e.eventsInOrder = append(e.eventsInOrder, event)
+			// override the event in the slice exposing the GlobalVersion to the caller
+			events[i].GlobalVersion = event.GlobalVersion
+		}
+		e.aggregateEvents[bucketName] = evBucket
 	}
-
-	e.aggregateEvents[bucketName] = evBucket
 	return nil
 }
 
@@ -126,3 +140,10 @@ func (m *Memory) All(start core.Version, count uint64) core.Fetcher {
 		return &iterator{events: events}, nil
 	}
 }
+
+// Head returns the global version of the latest saved event, 0 if there are no events
+func (m *Memory) Head(ctx context.Context) (core.Version, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return core.Version(len(m.eventsInOrder)), ctx.Err()
+}
@@ -73,25 +73,44 @@ func (s *SQLite) Save(events []core.Event) error {
 	if len(events) == 0 {
 		return nil
 	}
+	return s.SaveStreams([][]core.Event{events})
+}
 
+// SaveStreams persists the events of several aggregates in one transaction, if the events of one aggregate
+// can't be saved no events are saved
+func (s *SQLite) SaveStreams(streams [][]core.Event) error {
 	if s.lock != nil {
 		// prevent multiple writers
 		s.lock.Lock()
 		defer s.lock.Unlock()
 	}
-	aggregateID := events[0].AggregateID
-	aggregateType := events[0].AggregateType
 
 	tx, err := s.db.BeginTx(context.Background(), nil)
 	if err != nil {
 		return errors.New(fmt.Sprintf("could not start a write transaction, %v", err))
 	}
 	defer tx.Rollback()
+	for _, events := range streams {
+		err = s.saveEvents(tx, events)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// saveEvents inserts the events of an aggregate in the transaction
+func (s *SQLite) saveEvents(tx *sql.Tx, events []core.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	aggregateID := events[0].AggregateID
+	aggregateType := events[0].AggregateType
 
 	var currentVersion core.Version
 	var version int
 	selectStm := `Select version from events where id=? and type=? order by version desc limit 1`
-	err = tx.QueryRow(selectStm, aggregateID, aggregateType).Scan(&version)
+	err := tx.QueryRow(selectStm, aggregateID, aggregateType).Scan(&version)
 	if err != nil && err != sql.ErrNoRows {
 		return err
 	} else if err == sql.ErrNoRows {
@@ -121,7 +140,7 @@ func (s *SQLite) Save(events []core.Event) error {
 		// override the event in the slice exposing the GlobalVersion to the caller
 		events[i].GlobalVersion = core.Version(lastInsertedID)
 	}
-	return tx.Commit()
+	return nil
 }
 
 // Get the events from database
@@ -151,3 +170,10 @@ func (s *SQLite) All(start core.Version) core.Fetcher {
 		return &iter, nil
 	}
 }
+
+// Head returns the global version of the latest saved event, 0 if there are no events
+func (s *SQLite) Head(ctx context.Context) (core.Version, error) {
+	var seq int64
+	err := s.db.QueryRowContext(ctx, `Select coalesce(max(seq), 0) from events`).Scan(&seq)
+	return core.Version(seq), err
+}
@@ -0,0 +1,238 @@
+// Package fetcher contains combinators that wraps a core.Fetcher to filter, transform, limit and merge the fetched
+// events. All combinators return a core.Fetcher and can be used directly in a projection.
+package fetcher
+
+import (
+	"slices"
+
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/internal"
+)
+
+// Filter returns a fetcher with the events where keep returns true
+func Filter(f core.Fetcher, keep func(event core.Event) bool) core.Fetcher {
+	return func() (core.Iterator, error) {
+		iterator, err := f()
+		if err != nil {
+			return nil, err
+		}
+		return &filterIterator{iterator: iterator, keep: keep}, nil
+	}
+}
+
+// AggregateType returns a fetcher with the events from the aggregate types
+func AggregateType(f core.Fetcher, aggregateTypes ...string) core.Fetcher {
+	return Filter(f, func(event core.Event) bool {
+		return slices.Contains(aggregateTypes, event.AggregateType)
+	})
+}
+
+// Reason returns a fetcher with the events with the reasons
+func Reason(f core.Fetcher, reasons ...string) core.Fetcher {
+	return Filter(f, func(event core.Event) bool {
+		return slices.Contains(reasons, event.Reason)
+	})
+}
+
+// Metadata returns a fetcher with the events where keep returns true for the deserialized metadata. The metadata
+// is deserialized with the event encoder, an error is returned from the iterator if it fails.
+func Metadata(f core.Fetcher, keep func(metadata map[string]interface{}) bool) core.Fetcher {
+	return func() (core.Iterator, error) {
+		iterator, err := f()
+		if err != nil {
+			return nil, err
+		}
+		return &filterIterator{iterator: iterator, keepErr: func(event core.Event) (bool, error) {
+			metadata := make(map[string]interface{})
+			if event.Metadata != nil {
+				err := internal.EventEncoder.Deserialize(event.Metadata, &metadata)
+				if err != nil {
+					return false, err
+				}
+			}
+			return keep(metadata), nil
+		}}, nil
+	}
+}
+
+// Map returns a fetcher where each event is transformed by the map function, an error from the map function is
+// returned from the iterator
+func Map(f core.Fetcher, m func(event core.Event) (core.Event, error)) core.Fetcher {
+	return func() (core.Iterator, error) {
+		iterator, err := f()
+		if err != nil {
+			return nil, err
+		}
+		return &mapIterator{iterator: iterator, m: m}, nil
+	}
+}
+
+// Limit returns a fetcher that ends the event stream after n events
+func Limit(f core.Fetcher, n int) core.Fetcher {
+	var fetched int
+	return func() (core.Iterator, error) {
+		if fetched >= n {
+			return &emptyIterator{}, nil
+		}
+		iterator, err := f()
+		if err != nil {
+			return nil, err
+		}
+		return &stopIterator{iterator: iterator, stop: func(event core.Event) bool {
+			if fetched >= n {
+				return true
+			}
+			fetched++
+			return false
+		}}, nil
+	}
+}
+
+// StopAt returns a fetcher that ends the event stream after the event with the global version
+func StopAt(f core.Fetcher, version core.Version) core.Fetcher {
+	var passed bool
+	return func() (core.Iterator, error) {
+		if passed {
+			return &emptyIterator{}, nil
+		}
+		iterator, err := f()
+		if err != nil {
+			return nil, err
+		}
+		return &stopIterator{iterator: iterator, stop: func(event core.Event) bool {
+			if event.GlobalVersion > version {
+				passed = true
+			}
+			return passed
+		}}, nil
+	}
+}
+
+// filterIterator skips the events that should not be kept
+type filterIterator struct {
+	iterator core.Iterator
+	keep     func(event core.Event) bool
+	keepErr  func(event core.Event) (bool, error)
+	event    core.Event
+	err      error
+	position core.Version
+}
+
+func (i *filterIterator) Next() bool {
+	for i.iterator.Next() {
+		i.event, i.err = i.iterator.Value()
+		if i.err != nil {
+			return true
+		}
+		i.position = core.IteratorPosition(i.iterator, i.event.GlobalVersion)
+		if i.keepErr != nil {
+			var keep bool
+			keep, i.err = i.keepErr(i.event)
+			if keep || i.err != nil {
+				return true
+			}
+			continue
+		}
+		if i.keep(i.event) {
+			return true
+		}
+	}
+	i.position = core.IteratorPosition(i.iterator, i.position)
+	return false
+}
+
+func (i *filterIterator) Value() (core.Event, error) {
+	return i.event, i.err
+}
+
+func (i *filterIterator) Position() core.Version {
+	return i.position
+}
+
+func (i *filterIterator) Close() {
+	i.iterator.Close()
+}
+
+// mapIterator transforms the events
+type mapIterator struct {
+	iterator core.Iterator
+	m        func(event core.Event) (core.Event, error)
+	position core.Version
+}
+
+func (i *mapIterator) Next() bool {
+	if i.iterator.Next() {
+		return true
+	}
+	i.position = core.IteratorPosition(i.iterator, i.position)
+	return false
+}
+
+func (i *mapIterator) Value() (core.Event, error) {
+	event, err := i.iterator.Value()
+	if err != nil {
+		return core.Event{}, err
+	}
+	i.position = core.IteratorPosition(i.iterator, event.GlobalVersion)
+	return i.m(event)
+}
+
+func (i *mapIterator) Position() core.Version {
+	return i.position
+}
+
+func (i *mapIterator) Close() {
+	i.iterator.Close()
+}
+
+// stopIterator ends the iteration on the first event where stop returns true
+type stopIterator struct {
+	iterator core.Iterator
+	stop     func(event core.Event) bool
+	stopped  bool
+	event    core.Event
+	err      error
+	position core.Version
+}
+
+func (i *stopIterator) Next() bool {
+	if i.stopped {
+		return false
+	}
+	if !i.iterator.Next() {
+		i.position = core.IteratorPosition(i.iterator, i.position)
+		return false
+	}
+	i.event, i.err = i.iterator.Value()
+	if i.err == nil && i.stop(i.event) {
+		i.stopped = true
+		return false
+	}
+	i.position = core.IteratorPosition(i.iterator, i.event.GlobalVersion)
+	return true
+}
+
+func (i *stopIterator) Value() (core.Event, error) {
+	return i.event, i.err
+}
+
+// Position returns the global version of the last event before the stop
+func (i *stopIterator) Position() core.Version {
+	return i.position
+}
+
+func (i *stopIterator) Close() {
+	i.iterator.Close()
+}
+
+type emptyIterator struct{}
+
+func (i *emptyIterator) Next() bool {
+	return false
+}
+
+func (i *emptyIterator) Value() (core.Event, error) {
+	return core.Event{}, nil
+}
+
+func (i *emptyIterator) Close() {}
@@ -0,0 +1,274 @@
+package fetcher_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/fetcher"
+)
+
+// iterator iterates a slice of events
+type iterator struct {
+	events []core.Event
+	index  int
+	err    error
+}
+
+func (i *iterator) Next() bool {
+	if i.index >= len(i.events) {
+		return false
+	}
+	i.index++
+	return true
+}
+
+func (i *iterator) Value() (core.Event, error) {
+	return i.events[i.index-1], i.err
+}
+
+func (i *iterator) Close() {}
+
+// source returns a fetcher that returns the events in batches of count
+func source(count int, events ...core.Event) core.Fetcher {
+	return func() (core.Iterator, error) {
+		n := min(count, len(events))
+		batch := events[:n]
+		events = events[n:]
+		return &iterator{events: batch}, nil
+	}
+}
+
+func event(globalVersion core.Version, aggregateType, reason string) core.Event {
+	return core.Event{
+		AggregateID:   "123",
+		GlobalVersion: globalVersion,
+		AggregateType: aggregateType,
+		Reason:        reason,
+		Timestamp:     time.Unix(int64(globalVersion), 0),
+	}
+}
+
+// fetchAll fetches until the fetcher returns no events
+func fetchAll(t *testing.T, f core.Fetcher) []core.Event {
+	t.Helper()
+	var events []core.Event
+	for {
+		iterator, err := f()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ran bool
+		for iterator.Next() {
+			ran = true
+			event, err := iterator.Value()
+			if err != nil {
+				t.Fatal(err)
+			}
+			events = append(events, event)
+		}
+		iterator.Close()
+		if !ran {
+			return events
+		}
+	}
+}
+
+func versions(events []core.Event) []core.Version {
+	var v []core.Version
+	for _, e := range events {
+		v = append(v, e.GlobalVersion)
+	}
+	return v
+}
+
+func equal(t *testing.T, events []core.Event, expected ...core.Version) {
+	t.Helper()
+	got := versions(events)
+	if len(got) != len(expected) {
+		t.Fatalf("expected global versions %v got %v", expected, got)
+	}
+	for i := range got {
+		if got[i] != expected[i] {
+			t.Fatalf("expected global versions %v got %v", expected, got)
+		}
+	}
+}
+
+func TestAggregateType(t *testing.T) {
+	f := fetcher.AggregateType(source(2,
+		event(1, "Person", "Born"),
+		event(2, "Order", "Created"),
+		event(3, "Person", "AgedOneYear"),
+		event(4, "Account", "Opened"),
+	), "Person", "Account")
+	equal(t, fetchAll(t, f), 1, 3, 4)
+}
+
+func TestReason(t *testing.T) {
+	f := fetcher.Reason(source(10,
+		event(1, "Person", "Born"),
+		event(2, "Person", "AgedOneYear"),
+		event(3, "Person", "AgedOneYear"),
+	), "AgedOneYear")
+	equal(t, fetchAll(t, f), 2, 3)
+}
+
+func TestMetadata(t *testing.T) {
+	e1 := event(1, "Person", "Born")
+	e1.Metadata = []byte(`{"tenant":"a"}`)
+	e2 := event(2, "Person", "Born")
+	e2.Metadata = []byte(`{"tenant":"b"}`)
+	e3 := event(3, "Person", "Born")
+
+	f := fetcher.Metadata(source(10, e1, e2, e3), func(metadata map[string]interface{}) bool {
+		return metadata["tenant"] == "b"
+	})
+	equal(t, fetchAll(t, f), 2)
+
+	// the error from deserializing the metadata is returned from the iterator
+	e3.Metadata = []byte(`not json`)
+	iterator, err := fetcher.Metadata(source(10, e3), func(metadata map[string]interface{}) bool {
+		return true
+	})()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !iterator.Next() {
+		t.Fatal("expected the event with the error")
+	}
+	if _, err := iterator.Value(); err == nil {
+		t.Fatal("expected deserialize error")
+	}
+}
+
+func TestFilterError(t *testing.T) {
+	fetchErr := errors.New("fetch error")
+	f := fetcher.Reason(func() (core.Iterator, error) {
+		return nil, fetchErr
+	}, "Born")
+	_, err := f()
+	if !errors.Is(err, fetchErr) {
+		t.Fatalf("expected fetch error got %v", err)
+	}
+
+	valueErr := errors.New("value error")
+	i, err := fetcher.Reason(func() (core.Iterator, error) {
+		return &iterator{events: []core.Event{event(1, "Person", "AgedOneYear")}, err: valueErr}, nil
+	}, "Born")()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !i.Next() {
+		t.Fatal("expected the event with the error")
+	}
+	if _, err := i.Value(); !errors.Is(err, valueErr) {
+		t.Fatalf("expected value error got %v", err)
+	}
+}
+
+func TestMap(t *testing.T) {
+	f := fetcher.Map(source(10,
+		event(1, "Person", "Born"),
+		event(2, "Person", "AgedOneYear"),
+	), func(e core.Event) (core.Event, error) {
+		e.AggregateType = "Human"
+		return e, nil
+	})
+	events := fetchAll(t, f)
+	equal(t, events, 1, 2)
+	for _, e := range events {
+		if e.AggregateType != "Human" {
+			t.Fatalf("expected aggregate type Human got %s", e.AggregateType)
+		}
+	}
+}
+
+func TestLimit(t *testing.T) {
+	f := fetcher.Limit(source(2,
+		event(1, "Person", "Born"),
+		event(2, "Person", "AgedOneYear"),
+		event(3, "Person", "AgedOneYear"),
+		event(4, "Person", "AgedOneYear"),
+	), 3)
+	equal(t, fetchAll(t, f), 1, 2, 3)
+}
+
+func TestStopAt(t *testing.T) {
+	f := fetcher.StopAt(source(2,
+		event(1, "Person", "Born"),
+		event(2, "Person", "AgedOneYear"),
+		event(4, "Person", "AgedOneYear"),
+		event(5, "Person", "AgedOneYear"),
+	), 3)
+	equal(t, fetchAll(t, f), 1, 2)
+}
+
+func TestPosition(t *testing.T) {
+	// the filter skips the last events and the map passes on the position
+	f := fetcher.Map(fetcher.Reason(source(10,
+		event(1, "Person", "Born"),
+		event(2, "Person", "AgedOneYear"),
+		event(3, "Person", "AgedOneYear"),
+	), "Born"), func(e core.Event) (core.Event, error) {
+		return e, nil
+	})
+	i, err := f()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, ok := i.(core.PositionIterator)
+	if !ok {
+		t.Fatal("expected a position iterator")
+	}
+	for i.Next() {
+		if _, err := i.Value(); err != nil {
+			t.Fatal(err)
+		}
+		if p.Position() != 1 {
+			t.Fatalf("expected position 1 got %d", p.Position())
+		}
+	}
+	if p.Position() != 3 {
+		t.Fatalf("expected position 3 got %d", p.Position())
+	}
+}
+
+func TestMergeByGlobalVersion(t *testing.T) {
+	f := fetcher.MergeByGlobalVersion(
+		source(10, event(1, "Person", "Born"), event(4, "Person", "AgedOneYear")),
+		source(10, event(2, "Order", "Created"), event(3, "Order", "Paid"), event(5, "Order", "Shipped")),
+	)
+	equal(t, fetchAll(t, f), 1, 2, 3, 4, 5)
+}
+
+func TestMergeByTimestamp(t *testing.T) {
+	e1 := event(1, "Person", "Born")
+	e2 := event(1, "Order", "Created")
+	e2.Timestamp = e1.Timestamp.Add(time.Second * 2)
+	e3 := event(2, "Person", "AgedOneYear")
+	e3.Timestamp = e1.Timestamp.Add(time.Second)
+
+	f := fetcher.MergeByTimestamp(source(10, e1, e3), source(10, e2))
+	events := fetchAll(t, f)
+	for i := 1; i < len(events); i++ {
+		if events[i].Timestamp.Before(events[i-1].Timestamp) {
+			t.Fatalf("expected events in timestamp order got %v", events)
+		}
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events got %d", len(events))
+	}
+}
+
+func TestMergeFetchError(t *testing.T) {
+	fetchErr := errors.New("fetch error")
+	f := fetcher.MergeByGlobalVersion(source(10, event(1, "Person", "Born")), func() (core.Iterator, error) {
+		return nil, fetchErr
+	})
+	_, err := f()
+	if !errors.Is(err, fetchErr) {
+		t.Fatalf("expected fetch error got %v", err)
+	}
+}
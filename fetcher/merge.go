@@ -0,0 +1,101 @@
+package fetcher
+
+import (
+	"github.com/r23vme/eventsourcing/core"
+)
+
+// MergeByGlobalVersion returns a fetcher that fetches from all fetchers and returns the events in global version
+// order. The events are ordered within each fetch, events that a fetcher returns in a later fetch can be before
+// events that was already returned from the other fetchers.
+func MergeByGlobalVersion(fetchers ...core.Fetcher) core.Fetcher {
+	return merge(fetchers, func(a, b core.Event) bool {
+		return a.GlobalVersion < b.GlobalVersion
+	})
+}
+
+// MergeByTimestamp returns a fetcher that fetches from all fetchers and returns the events in timestamp order,
+// events with the same timestamp are returned in the order of the fetchers. As with MergeByGlobalVersion the
+// events are ordered within each fetch.
+func MergeByTimestamp(fetchers ...core.Fetcher) core.Fetcher {
+	return merge(fetchers, func(a, b core.Event) bool {
+		return a.Timestamp.Before(b.Timestamp)
+	})
+}
+
+func merge(fetchers []core.Fetcher, less func(a, b core.Event) bool) core.Fetcher {
+	return func() (core.Iterator, error) {
+		m := mergeIterator{less: less}
+		for _, f := range fetchers {
+			iterator, err := f()
+			if err != nil {
+				m.Close()
+				return nil, err
+			}
+			m.heads = append(m.heads, &head{iterator: iterator})
+		}
+		return &m, nil
+	}
+}
+
+// head is the next event of an iterator in the merge
+type head struct {
+	iterator core.Iterator
+	ok       bool
+	event    core.Event
+	err      error
+}
+
+func (h *head) advance() {
+	h.ok = h.iterator.Next()
+	if h.ok {
+		h.event, h.err = h.iterator.Value()
+	}
+}
+
+// mergeIterator returns the lowest event of the iterator heads
+type mergeIterator struct {
+	heads   []*head
+	less    func(a, b core.Event) bool
+	started bool
+	current *head
+}
+
+func (m *mergeIterator) Next() bool {
+	if !m.started {
+		m.started = true
+		for _, h := range m.heads {
+			h.advance()
+		}
+	} else if m.current != nil {
+		m.current.advance()
+	}
+
+	m.current = nil
+	for _, h := range m.heads {
+		if !h.ok {
+			continue
+		}
+		// return the error before any event
+		if h.err != nil {
+			m.current = h
+			return true
+		}
+		if m.current == nil || m.less(h.event, m.current.event) {
+			m.current = h
+		}
+	}
+	return m.current != nil
+}
+
+func (m *mergeIterator) Value() (core.Event, error) {
+	if m.current == nil {
+		return core.Event{}, nil
+	}
+	return m.current.event, m.current.err
+}
+
+func (m *mergeIterator) Close() {
+	for _, h := range m.heads {
+		h.iterator.Close()
+	}
+}
@@ -0,0 +1,70 @@
+package eventsourcing
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/fetcher"
+	"github.com/r23vme/eventsourcing/internal"
+)
+
+// Handlers builds a projection where each event is handled by the handler registered on the type of the event
+// data. Events without a handler are skipped, when possible before they are deserialized.
+type Handlers struct {
+	handlers map[reflect.Type]contextCallbackFunc
+	reasons  map[string]struct{}
+}
+
+// NewHandlers creates an empty set of handlers, register handlers with Handle
+func NewHandlers() *Handlers {
+	return &Handlers{
+		handlers: make(map[reflect.Type]contextCallbackFunc),
+		reasons:  make(map[string]struct{}),
+	}
+}
+
+// Handle registers the handler for the event data type T, ex. *Born. The event data is passed typed to the handler.
+// A handler registered again on the same type replaces the previous handler.
+func Handle[T any](h *Handlers, handler func(ctx context.Context, event Event, data T) error) *Handlers {
+	typ, reason := internal.EventType[T]()
+	h.handlers[typ] = func(ctx context.Context, event Event) error {
+		data, ok := internal.EventData[T](event.Data())
+		if !ok {
+			return nil
+		}
+		return handler(ctx, event, data)
+	}
+	h.reasons[reason] = struct{}{}
+	return h
+}
+
+// Callback dispatches the event to the handler registered on the type of its data
+func (h *Handlers) Callback(ctx context.Context, event Event) error {
+	handler, ok := h.handlers[reflect.TypeOf(event.Data())]
+	if !ok {
+		return nil
+	}
+	return handler(ctx, event)
+}
+
+// Fetcher skips the events without a handler before they are deserialized. The events are skipped on reason,
+// events from other aggregates with the same reason are deserialized and skipped by the callback.
+func (h *Handlers) Fetcher(f core.Fetcher) core.Fetcher {
+	return fetcher.Filter(f, func(event core.Event) bool {
+		_, ok := h.reasons[event.Reason]
+		return ok
+	})
+}
+
+// Projection creates a projection that handles the events with the handlers
+func (h *Handlers) Projection(fetchF core.Fetcher) *Projection {
+	return NewProjectionContext(h.Fetcher(fetchF), h.Callback)
+}
+
+// CheckpointProjection creates a checkpoint projection that handles the events with the handlers
+func (h *Handlers) CheckpointProjection(name string, cs core.CheckpointStore, fetchFrom core.FetcherFrom) *Projection {
+	return NewCheckpointProjectionContext(name, cs, func(start core.Version) core.Fetcher {
+		return h.Fetcher(fetchFrom(start))
+	}, h.Callback)
+}
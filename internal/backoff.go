@@ -0,0 +1,22 @@
+package internal
+
+import (
+	"math"
+	"time"
+)
+
+// Backoff returns the wait before the retry attempt, starting from 0. The wait starts on base and is doubled
+// for each attempt, it's capped at max if max is set.
+func Backoff(base, max time.Duration, attempt int) time.Duration {
+	backoff := base
+	for i := 0; i < attempt; i++ {
+		if (max > 0 && backoff >= max) || backoff > math.MaxInt64/2 {
+			break
+		}
+		backoff *= 2
+	}
+	if max > 0 && backoff > max {
+		return max
+	}
+	return backoff
+}
@@ -86,3 +86,25 @@ func eventToFunc(event interface{}) registerFunc {
 func aggregateType(a aggregate) string {
 	return reflect.TypeOf(a).Elem().Name()
 }
+
+// EventType returns the type the event data T is deserialized into, a pointer to the event type, and the
+// reason of the event which is the name of the event type
+func EventType[T any]() (reflect.Type, string) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Pointer {
+		typ = reflect.PointerTo(typ)
+	}
+	return typ, typ.Elem().Name()
+}
+
+// EventData returns the event data as T, where T can be the event type or a pointer to it
+func EventData[T any](data interface{}) (T, bool) {
+	switch d := data.(type) {
+	case T:
+		return d, true
+	case *T:
+		return *d, true
+	}
+	var zero T
+	return zero, false
+}
@@ -25,13 +25,18 @@ func (i *Iterator) Value() (Event, error) {
 	if err != nil {
 		return Event{}, err
 	}
+	return toEvent(event)
+}
+
+// toEvent deserialize the data and metadata of the core event into an Event
+func toEvent(event core.Event) (Event, error) {
 	// apply the event to the aggregate
 	f, found := internal.GlobalRegister.EventRegistered(event)
 	if !found {
 		return Event{}, fmt.Errorf("event not registered, aggregate type: %s, reason: %s, global version: %d, %w", event.AggregateType, event.Reason, event.GlobalVersion, ErrEventNotRegistered)
 	}
 	data := f()
-	err = internal.EventEncoder.Deserialize(event.Data, &data)
+	err := internal.EventEncoder.Deserialize(event.Data, &data)
 	if err != nil {
 		return Event{}, err
 	}
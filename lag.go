@@ -0,0 +1,61 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNoHead is returned when the lag is requested on a projection without the Head property set
+var ErrNoHead = errors.New("projection has no head function")
+
+// Lag is how far a projection is behind the event store
+type Lag struct {
+	Name                     string
+	Head                     Version
+	LastHandledGlobalVersion Version
+	// Events is the number of global versions between the last handled event and the head
+	Events uint64
+	// Age is the time since the last handled event was created, 0 when the projection has reached the head
+	Age time.Duration
+}
+
+// Lag returns how far the projection is behind the head of the event store
+func (p *Projection) Lag(ctx context.Context) (Lag, error) {
+	if p.Head == nil {
+		return Lag{}, ErrNoHead
+	}
+	head, err := p.Head(ctx)
+	if err != nil {
+		return Lag{}, err
+	}
+	lag := Lag{
+		Name:                     p.Name,
+		Head:                     Version(head),
+		LastHandledGlobalVersion: Version(p.position.Load()),
+	}
+	if lag.Head <= lag.LastHandledGlobalVersion {
+		return lag, nil
+	}
+	lag.Events = uint64(lag.Head - lag.LastHandledGlobalVersion)
+	if handledAt := p.handledAt.Load(); handledAt != 0 {
+		lag.Age = time.Since(time.Unix(0, handledAt))
+	}
+	return lag, nil
+}
+
+// Lag returns the lag of the projections in the group that has the Head property set
+func (g *ProjectionGroup) Lag(ctx context.Context) ([]Lag, error) {
+	lags := make([]Lag, 0)
+	for _, projection := range g.list() {
+		if projection.Head == nil {
+			continue
+		}
+		lag, err := projection.Lag(ctx)
+		if err != nil {
+			return nil, err
+		}
+		lags = append(lags, lag)
+	}
+	return lags, nil
+}
@@ -0,0 +1,135 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+// Lease makes a projection run only in the process holding the lease on the projection name. The lease is
+// renewed while the projection runs and if it's lost the projection stops until the lease is acquired again.
+type Lease struct {
+	Store core.LeaseStore
+	// Owner identifies the process, ex. the host name
+	Owner string
+	// TTL is how long the lease is valid without being renewed, default 15 seconds
+	TTL time.Duration
+	// Renew is how often the lease is renewed and how often a process without the lease tries to acquire it,
+	// default a third of the TTL
+	Renew time.Duration
+
+	leader sync.Mutex
+	held   bool
+}
+
+// Leader returns true if the process holds the lease
+func (l *Lease) Leader() bool {
+	l.leader.Lock()
+	defer l.leader.Unlock()
+	return l.held
+}
+
+func (l *Lease) setLeader(held bool) {
+	l.leader.Lock()
+	defer l.leader.Unlock()
+	l.held = held
+}
+
+func (l *Lease) ttl() time.Duration {
+	if l.TTL > 0 {
+		return l.TTL
+	}
+	return time.Second * 15
+}
+
+func (l *Lease) renew() time.Duration {
+	if l.Renew > 0 {
+		return l.Renew
+	}
+	return l.ttl() / 3
+}
+
+// runLeased runs the projection while the lease on the projection name is held
+func (p *Projection) runLeased(ctx context.Context, pace time.Duration, stop <-chan struct{}) error {
+	for {
+		err := p.acquire(ctx, stop)
+		if err != nil {
+			return err
+		}
+		if p.stopped() {
+			p.Lease.setLeader(false)
+			return p.Lease.Store.Release(p.Name, p.Lease.Owner)
+		}
+
+		leaderCtx, cancel := context.WithCancel(ctx)
+		renewed := make(chan struct{})
+		go func() {
+			defer close(renewed)
+			p.keepLease(leaderCtx, cancel)
+		}()
+		err = p.loop(leaderCtx, pace, stop)
+		cancel()
+		<-renewed
+		p.Lease.setLeader(false)
+		p.Lease.Store.Release(p.Name, p.Lease.Owner)
+
+		if ctx.Err() != nil || !errors.Is(err, context.Canceled) {
+			return err
+		}
+		// the lease was lost, another process could have handled events after the checkpoint
+		if p.fetchFrom != nil {
+			p.exec.Lock()
+			p.fetchF = nil
+			p.exec.Unlock()
+		}
+	}
+}
+
+// acquire blocks until the lease is acquired, the context is done or the projection is stopped
+func (p *Projection) acquire(ctx context.Context, stop <-chan struct{}) error {
+	for {
+		err := p.Lease.Store.Acquire(ctx, p.Name, p.Lease.Owner, p.Lease.ttl())
+		if err == nil {
+			p.Lease.setLeader(true)
+			return nil
+		}
+		if !errors.Is(err, core.ErrLeaseHeld) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-stop:
+			return nil
+		case <-time.After(p.Lease.renew()):
+		case f := <-p.trigger:
+			// the projection is not running, release a sync trigger directly
+			f()
+		}
+	}
+}
+
+// keepLease renews the lease until the context is done, if the lease is lost or could not be renewed
+// before it expired the cancel function is called
+func (p *Projection) keepLease(ctx context.Context, cancel context.CancelFunc) {
+	renewedAt := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.Lease.renew()):
+		}
+		err := p.Lease.Store.Acquire(ctx, p.Name, p.Lease.Owner, p.Lease.ttl())
+		if err == nil {
+			renewedAt = time.Now()
+			continue
+		}
+		if errors.Is(err, core.ErrLeaseHeld) || time.Since(renewedAt) >= p.Lease.ttl() {
+			cancel()
+			return
+		}
+	}
+}
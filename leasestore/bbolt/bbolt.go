@@ -0,0 +1,219 @@
+package bbolt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+type lease struct {
+	owner   string
+	expires time.Time
+	db      *bbolt.DB
+}
+
+// BBolt is a lease store where a lease is the exclusive file lock on a bbolt database in a directory.
+// Between processes the lease is held until it's released or the process holding it exits, the ttl only
+// applies between owners in the same process. Members of a group are held the same way by a file lock
+// in a directory for the group.
+type BBolt struct {
+	dir     string
+	leases  map[string]*lease
+	members map[string]map[string]*lease
+	lock    sync.Mutex
+}
+
+// New creates a lease store with the lock files in the directory
+func New(dir string) (*BBolt, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	return &BBolt{
+		dir:     dir,
+		leases:  make(map[string]*lease),
+		members: make(map[string]map[string]*lease),
+	}, nil
+}
+
+// Close releases all leases held by the store
+func (b *BBolt) Close() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	var err error
+	for name, l := range b.leases {
+		err = errors.Join(err, l.db.Close())
+		delete(b.leases, name)
+	}
+	for group, members := range b.members {
+		for name, m := range members {
+			err = errors.Join(err, m.db.Close())
+			os.Remove(b.memberPath(group, name))
+		}
+		delete(b.members, group)
+	}
+	return err
+}
+
+// Acquire takes or renews the lease for the owner
+func (b *BBolt) Acquire(ctx context.Context, name, owner string, ttl time.Duration) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now()
+	if l, ok := b.leases[name]; ok {
+		if l.owner != owner && l.expires.After(now) {
+			return core.ErrLeaseHeld
+		}
+		// the file lock is kept when the lease moves between owners in the process
+		l.owner = owner
+		l.expires = now.Add(ttl)
+		return nil
+	}
+
+	db, err := lock(filepath.Join(b.dir, url.PathEscape(name)+".lock"))
+	if errors.Is(err, bbolt.ErrTimeout) {
+		return core.ErrLeaseHeld
+	}
+	if err != nil {
+		return err
+	}
+	b.leases[name] = &lease{owner: owner, expires: now.Add(ttl), db: db}
+	return nil
+}
+
+// Release gives up the lease if it's held by the owner
+func (b *BBolt) Release(name, owner string) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	l, ok := b.leases[name]
+	if !ok || l.owner != owner {
+		return nil
+	}
+	delete(b.leases, name)
+	return l.db.Close()
+}
+
+// Heartbeat marks the member as live and returns the live members in the group sorted by name
+func (b *BBolt) Heartbeat(ctx context.Context, group, member string, ttl time.Duration) ([]string, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	dir := b.groupDir(group)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	members, ok := b.members[group]
+	if !ok {
+		members = make(map[string]*lease)
+		b.members[group] = members
+	}
+
+	now := time.Now()
+	path := b.memberPath(group, member)
+	m, ok := members[member]
+	if ok {
+		// the lock file could have been removed as stale by another process before it was locked
+		if _, err := os.Stat(path); err != nil {
+			m.db.Close()
+			ok = false
+		}
+	}
+	if !ok {
+		db, err := lock(path)
+		if errors.Is(err, bbolt.ErrTimeout) {
+			return nil, fmt.Errorf("member %s is live in another process", member)
+		}
+		if err != nil {
+			return nil, err
+		}
+		m = &lease{owner: member, db: db}
+		members[member] = m
+	}
+	m.expires = now.Add(ttl)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	live := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name, err := url.PathUnescape(strings.TrimSuffix(entry.Name(), ".lock"))
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if m, ok := members[name]; ok {
+			if m.expires.Before(now) {
+				m.db.Close()
+				os.Remove(path)
+				delete(members, name)
+				continue
+			}
+			live = append(live, name)
+			continue
+		}
+		// the member is live as long as another process holds the lock
+		db, err := lock(path)
+		if errors.Is(err, bbolt.ErrTimeout) {
+			live = append(live, name)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		db.Close()
+		os.Remove(path)
+	}
+	sort.Strings(live)
+	return live, nil
+}
+
+// Leave removes the member from the group
+func (b *BBolt) Leave(group, member string) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	m, ok := b.members[group][member]
+	if !ok {
+		return nil
+	}
+	delete(b.members[group], member)
+	err := m.db.Close()
+	os.Remove(b.memberPath(group, member))
+	return err
+}
+
+func (b *BBolt) groupDir(group string) string {
+	return filepath.Join(b.dir, url.PathEscape(group)+".members")
+}
+
+func (b *BBolt) memberPath(group, member string) string {
+	return filepath.Join(b.groupDir(group), url.PathEscape(member)+".lock")
+}
+
+// lock opens the bbolt database holding the exclusive file lock, bbolt.ErrTimeout is returned if another
+// process holds the lock
+func lock(path string) (*bbolt.DB, error) {
+	return bbolt.Open(path, 0600, &bbolt.Options{Timeout: time.Millisecond})
+}
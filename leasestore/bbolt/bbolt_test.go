@@ -0,0 +1,108 @@
+package bbolt_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/core/testsuite"
+	ls "github.com/r23vme/eventsourcing/leasestore/bbolt"
+)
+
+func TestSuite(t *testing.T) {
+	dir := t.TempDir()
+	f := func() (core.LeaseStore, func(), error) {
+		store, err := ls.New(dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() {
+			store.Close()
+		}, nil
+	}
+	testsuite.TestLeaseStore(t, f)
+}
+
+func TestMemberSuite(t *testing.T) {
+	dir := t.TempDir()
+	f := func() (core.MemberStore, func(), error) {
+		store, err := ls.New(dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() {
+			store.Close()
+		}, nil
+	}
+	testsuite.TestMemberStore(t, f)
+}
+
+func TestFileLock(t *testing.T) {
+	dir := t.TempDir()
+	// two stores on the same directory act as two processes
+	s1, err := ls.New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s1.Close()
+	s2, err := ls.New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s2.Close()
+
+	err = s1.Acquire(context.Background(), "persons", "a", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s2.Acquire(context.Background(), "persons", "b", time.Second)
+	if !errors.Is(err, core.ErrLeaseHeld) {
+		t.Fatalf("expected core.ErrLeaseHeld got %v", err)
+	}
+	err = s1.Release("persons", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s2.Acquire(context.Background(), "persons", "b", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMemberFileLock(t *testing.T) {
+	dir := t.TempDir()
+	s1, err := ls.New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := ls.New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s2.Close()
+
+	_, err = s1.Heartbeat(context.Background(), "persons", "a", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	members, err := s2.Heartbeat(context.Background(), "persons", "b", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(members, []string{"a", "b"}) {
+		t.Fatalf("expected members [a b] got %v", members)
+	}
+
+	// the member is not live when the process holding it exits
+	s1.Close()
+	members, err = s2.Heartbeat(context.Background(), "persons", "b", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(members, []string{"b"}) {
+		t.Fatalf("expected members [b] got %v", members)
+	}
+}
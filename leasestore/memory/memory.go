@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+type lease struct {
+	owner   string
+	expires time.Time
+}
+
+type Memory struct {
+	leases  map[string]lease
+	members map[string]map[string]time.Time
+	lock    sync.Mutex
+}
+
+// Create in memory lease store
+func Create() *Memory {
+	return &Memory{
+		leases:  make(map[string]lease),
+		members: make(map[string]map[string]time.Time),
+	}
+}
+
+func (m *Memory) Close() {
+
+}
+
+// Acquire takes or renews the lease for the owner
+func (m *Memory) Acquire(ctx context.Context, name, owner string, ttl time.Duration) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := time.Now()
+	l, ok := m.leases[name]
+	if ok && l.owner != owner && l.expires.After(now) {
+		return core.ErrLeaseHeld
+	}
+	m.leases[name] = lease{owner: owner, expires: now.Add(ttl)}
+	return nil
+}
+
+// Release gives up the lease if it's held by the owner
+func (m *Memory) Release(name, owner string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if l, ok := m.leases[name]; ok && l.owner == owner {
+		delete(m.leases, name)
+	}
+	return nil
+}
+
+// Heartbeat marks the member as live and returns the live members in the group sorted by name
+func (m *Memory) Heartbeat(ctx context.Context, group, member string, ttl time.Duration) ([]string, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := time.Now()
+	members, ok := m.members[group]
+	if !ok {
+		members = make(map[string]time.Time)
+		m.members[group] = members
+	}
+	members[member] = now.Add(ttl)
+
+	live := make([]string, 0, len(members))
+	for name, expires := range members {
+		if expires.Before(now) {
+			delete(members, name)
+			continue
+		}
+		live = append(live, name)
+	}
+	sort.Strings(live)
+	return live, nil
+}
+
+// Leave removes the member from the group
+func (m *Memory) Leave(group, member string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.members[group], member)
+	return nil
+}
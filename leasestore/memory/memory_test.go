@@ -0,0 +1,25 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/core/testsuite"
+	"github.com/r23vme/eventsourcing/leasestore/memory"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (core.LeaseStore, func(), error) {
+		ls := memory.Create()
+		return ls, func() { ls.Close() }, nil
+	}
+	testsuite.TestLeaseStore(t, f)
+}
+
+func TestMemberSuite(t *testing.T) {
+	f := func() (core.MemberStore, func(), error) {
+		ms := memory.Create()
+		return ms, func() { ms.Close() }, nil
+	}
+	testsuite.TestMemberStore(t, f)
+}
@@ -0,0 +1,89 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const createTablePostgres = `CREATE TABLE IF NOT EXISTS leases (
+    name VARCHAR NOT NULL PRIMARY KEY,
+    owner VARCHAR NOT NULL,
+    expires BIGINT NOT NULL
+);`
+
+const createMembersTablePostgres = `CREATE TABLE IF NOT EXISTS members (
+    name VARCHAR NOT NULL,
+    member VARCHAR NOT NULL,
+    expires BIGINT NOT NULL,
+    PRIMARY KEY (name, member)
+);`
+
+type Postgres struct {
+	db *sql.DB
+}
+
+// NewPostgres connection to database
+func NewPostgres(db *sql.DB) (*Postgres, error) {
+	if err := migrate(db, []string{
+		createTablePostgres,
+		createMembersTablePostgres,
+	}); err != nil {
+		return nil, err
+	}
+	return &Postgres{
+		db: db,
+	}, nil
+}
+
+// Close the connection
+func (s *Postgres) Close() {
+	s.db.Close()
+}
+
+// Acquire takes or renews the lease for the owner
+func (s *Postgres) Acquire(ctx context.Context, name, owner string, ttl time.Duration) error {
+	now := time.Now()
+	statement := `INSERT INTO leases (name, owner, expires) VALUES ($1, $2, $3) ON CONFLICT (name) DO UPDATE SET owner = EXCLUDED.owner, expires = EXCLUDED.expires WHERE leases.owner = EXCLUDED.owner OR leases.expires < $4`
+	res, err := s.db.ExecContext(ctx, statement, name, owner, now.Add(ttl).UnixNano(), now.UnixNano())
+	if err != nil {
+		return err
+	}
+	return acquired(res)
+}
+
+// Release gives up the lease if it's held by the owner
+func (s *Postgres) Release(name, owner string) error {
+	_, err := s.db.Exec(`DELETE FROM leases WHERE name = $1 AND owner = $2`, name, owner)
+	return err
+}
+
+// Heartbeat marks the member as live and returns the live members in the group sorted by name
+func (s *Postgres) Heartbeat(ctx context.Context, group, member string, ttl time.Duration) ([]string, error) {
+	now := time.Now()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT INTO members (name, member, expires) VALUES ($1, $2, $3) ON CONFLICT (name, member) DO UPDATE SET expires = EXCLUDED.expires`, group, member, now.Add(ttl).UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(`DELETE FROM members WHERE name = $1 AND expires < $2`, group, now.UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	members, err := queryMembers(tx, `SELECT member FROM members WHERE name = $1 ORDER BY member ASC`, group)
+	if err != nil {
+		return nil, err
+	}
+	return members, tx.Commit()
+}
+
+// Leave removes the member from the group
+func (s *Postgres) Leave(group, member string) error {
+	_, err := s.db.Exec(`DELETE FROM members WHERE name = $1 AND member = $2`, group, member)
+	return err
+}
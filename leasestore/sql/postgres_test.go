@@ -0,0 +1,77 @@
+package sql_test
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/core/testsuite"
+	"github.com/r23vme/eventsourcing/leasestore/sql"
+)
+
+func TestSuitePostgres(t *testing.T) {
+	ctx := context.Background()
+
+	// Set up the PostgreSQL container request
+	req := testcontainers.ContainerRequest{
+		Image:        "postgres:16", // Use a specific version
+		ExposedPorts: []string{"5432/tcp"},
+		Env: map[string]string{
+			"POSTGRES_USER":     "test",
+			"POSTGRES_PASSWORD": "secret",
+			"POSTGRES_DB":       "testdb",
+		},
+		WaitingFor: wait.ForListeningPort("5432/tcp").WithStartupTimeout(30 * time.Second),
+	}
+	// Start the container
+	postgresContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		t.Fatalf("failed to start container: %v", err)
+	}
+	defer postgresContainer.Terminate(ctx)
+
+	// Get container host and port
+	host, _ := postgresContainer.Host(ctx)
+	port, _ := postgresContainer.MappedPort(ctx, "5432")
+
+	// Build the DSN
+	dsn := fmt.Sprintf("host=%s port=%s user=test password=secret dbname=testdb sslmode=disable", host, port.Port())
+
+	f := func() (core.LeaseStore, func(), error) {
+		// Connect using database/sql
+		db, err := gosql.Open("postgres", dsn)
+		if err != nil {
+			return nil, nil, fmt.Errorf("db open failed: %w", err)
+		}
+		// Test the connection
+		err = db.Ping()
+		if err != nil {
+			return nil, nil, err
+		}
+		ls, err := sql.NewPostgres(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ls, func() {
+			db.Close()
+		}, nil
+	}
+	testsuite.TestLeaseStore(t, f)
+	testsuite.TestMemberStore(t, func() (core.MemberStore, func(), error) {
+		ls, closeFunc, err := f()
+		if err != nil {
+			return nil, nil, err
+		}
+		return ls.(core.MemberStore), closeFunc, nil
+	})
+}
@@ -0,0 +1,125 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+const createTableSQLite = `
+CREATE TABLE IF NOT EXISTS leases (
+	name     VARCHAR NOT NULL PRIMARY KEY,
+	owner    VARCHAR NOT NULL,
+	expires  INTEGER NOT NULL
+);`
+
+const createMembersTableSQLite = `
+CREATE TABLE IF NOT EXISTS members (
+	name     VARCHAR NOT NULL,
+	member   VARCHAR NOT NULL,
+	expires  INTEGER NOT NULL,
+	PRIMARY KEY (name, member)
+);`
+
+type SQLite struct {
+	db *sql.DB
+}
+
+// NewSQLite connection to database
+func NewSQLite(db *sql.DB) (*SQLite, error) {
+	if err := migrate(db, []string{
+		createTableSQLite,
+		createMembersTableSQLite,
+	}); err != nil {
+		return nil, err
+	}
+	return &SQLite{
+		db: db,
+	}, nil
+}
+
+// Close the connection
+func (s *SQLite) Close() {
+	s.db.Close()
+}
+
+// Acquire takes or renews the lease for the owner
+func (s *SQLite) Acquire(ctx context.Context, name, owner string, ttl time.Duration) error {
+	now := time.Now()
+	statement := `INSERT INTO leases (name, owner, expires) VALUES ($1, $2, $3) ON CONFLICT(name) DO UPDATE SET owner=excluded.owner, expires=excluded.expires WHERE leases.owner=excluded.owner OR leases.expires<$4`
+	res, err := s.db.ExecContext(ctx, statement, name, owner, now.Add(ttl).UnixNano(), now.UnixNano())
+	if err != nil {
+		return err
+	}
+	return acquired(res)
+}
+
+// Release gives up the lease if it's held by the owner
+func (s *SQLite) Release(name, owner string) error {
+	_, err := s.db.Exec(`DELETE FROM leases WHERE name=$1 AND owner=$2`, name, owner)
+	return err
+}
+
+// Heartbeat marks the member as live and returns the live members in the group sorted by name
+func (s *SQLite) Heartbeat(ctx context.Context, group, member string, ttl time.Duration) ([]string, error) {
+	now := time.Now()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT INTO members (name, member, expires) VALUES ($1, $2, $3) ON CONFLICT(name, member) DO UPDATE SET expires=excluded.expires`, group, member, now.Add(ttl).UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(`DELETE FROM members WHERE name=$1 AND expires<$2`, group, now.UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	members, err := queryMembers(tx, `SELECT member FROM members WHERE name=$1 ORDER BY member ASC`, group)
+	if err != nil {
+		return nil, err
+	}
+	return members, tx.Commit()
+}
+
+// Leave removes the member from the group
+func (s *SQLite) Leave(group, member string) error {
+	_, err := s.db.Exec(`DELETE FROM members WHERE name=$1 AND member=$2`, group, member)
+	return err
+}
+
+// queryMembers returns the member names from the query
+func queryMembers(tx *sql.Tx, query string, args ...any) ([]string, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := make([]string, 0)
+	for rows.Next() {
+		var member string
+		err = rows.Scan(&member)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+// acquired returns ErrLeaseHeld if the lease was not inserted or updated
+func acquired(res sql.Result) error {
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return core.ErrLeaseHeld
+	}
+	return nil
+}
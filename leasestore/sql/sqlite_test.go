@@ -0,0 +1,47 @@
+package sql_test
+
+import (
+	sqldriver "database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/core/testsuite"
+	"github.com/r23vme/eventsourcing/leasestore/sql"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (core.LeaseStore, func(), error) {
+		return leasestore()
+	}
+	testsuite.TestLeaseStore(t, f)
+}
+
+func leasestore() (*sql.SQLite, func(), error) {
+	db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+	err = db.Ping()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store, err := sql.NewSQLite(db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return store, func() {
+		store.Close()
+	}, nil
+}
+
+func TestMemberSuite(t *testing.T) {
+	f := func() (core.MemberStore, func(), error) {
+		return leasestore()
+	}
+	testsuite.TestMemberStore(t, f)
+}
@@ -0,0 +1,104 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const createTableSQLServer = `IF OBJECT_ID('[leases]', 'U') IS NULL
+BEGIN
+    CREATE TABLE [leases] (
+        [name] NVARCHAR(255) NOT NULL PRIMARY KEY,
+        [owner] NVARCHAR(255) NOT NULL,
+        [expires] BIGINT NOT NULL
+    );
+END`
+
+const createMembersTableSQLServer = `IF OBJECT_ID('[members]', 'U') IS NULL
+BEGIN
+    CREATE TABLE [members] (
+        [name] NVARCHAR(255) NOT NULL,
+        [member] NVARCHAR(255) NOT NULL,
+        [expires] BIGINT NOT NULL,
+        PRIMARY KEY ([name], [member])
+    );
+END`
+
+type SQLServer struct {
+	db *sql.DB
+}
+
+// NewSQLServer connection to database
+func NewSQLServer(db *sql.DB) (*SQLServer, error) {
+	if err := migrate(db, []string{
+		createTableSQLServer,
+		createMembersTableSQLServer,
+	}); err != nil {
+		return nil, err
+	}
+	return &SQLServer{
+		db: db,
+	}, nil
+}
+
+// Close the connection
+func (s *SQLServer) Close() {
+	s.db.Close()
+}
+
+// Acquire takes or renews the lease for the owner
+func (s *SQLServer) Acquire(ctx context.Context, name, owner string, ttl time.Duration) error {
+	now := time.Now()
+	statement := `MERGE [leases] WITH (HOLDLOCK) AS target
+USING (SELECT @name AS [name], @owner AS [owner], @expires AS [expires]) AS source
+ON target.[name] = source.[name]
+WHEN MATCHED AND (target.[owner] = source.[owner] OR target.[expires] < @now) THEN UPDATE SET [owner] = source.[owner], [expires] = source.[expires]
+WHEN NOT MATCHED THEN INSERT ([name], [owner], [expires]) VALUES (source.[name], source.[owner], source.[expires]);`
+	res, err := s.db.ExecContext(ctx, statement, sql.Named("name", name), sql.Named("owner", owner), sql.Named("expires", now.Add(ttl).UnixNano()), sql.Named("now", now.UnixNano()))
+	if err != nil {
+		return err
+	}
+	return acquired(res)
+}
+
+// Release gives up the lease if it's held by the owner
+func (s *SQLServer) Release(name, owner string) error {
+	_, err := s.db.Exec(`DELETE FROM [leases] WHERE [name] = @name AND [owner] = @owner;`, sql.Named("name", name), sql.Named("owner", owner))
+	return err
+}
+
+// Heartbeat marks the member as live and returns the live members in the group sorted by name
+func (s *SQLServer) Heartbeat(ctx context.Context, group, member string, ttl time.Duration) ([]string, error) {
+	now := time.Now()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	statement := `MERGE [members] WITH (HOLDLOCK) AS target
+USING (SELECT @name AS [name], @member AS [member], @expires AS [expires]) AS source
+ON target.[name] = source.[name] AND target.[member] = source.[member]
+WHEN MATCHED THEN UPDATE SET [expires] = source.[expires]
+WHEN NOT MATCHED THEN INSERT ([name], [member], [expires]) VALUES (source.[name], source.[member], source.[expires]);`
+	_, err = tx.Exec(statement, sql.Named("name", group), sql.Named("member", member), sql.Named("expires", now.Add(ttl).UnixNano()))
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(`DELETE FROM [members] WHERE [name] = @name AND [expires] < @now;`, sql.Named("name", group), sql.Named("now", now.UnixNano()))
+	if err != nil {
+		return nil, err
+	}
+	members, err := queryMembers(tx, `SELECT [member] FROM [members] WHERE [name] = @name ORDER BY [member] ASC;`, sql.Named("name", group))
+	if err != nil {
+		return nil, err
+	}
+	return members, tx.Commit()
+}
+
+// Leave removes the member from the group
+func (s *SQLServer) Leave(group, member string) error {
+	_, err := s.db.Exec(`DELETE FROM [members] WHERE [name] = @name AND [member] = @member;`, sql.Named("name", group), sql.Named("member", member))
+	return err
+}
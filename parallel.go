@@ -0,0 +1,145 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+)
+
+// slot is an event dispatched to a worker
+type slot struct {
+	event Event
+	done  bool
+}
+
+// watermark keeps the dispatched events in global version order and moves forward when the
+// lowest dispatched event is completed
+type watermark struct {
+	lock     sync.Mutex
+	slots    []*slot
+	lastDone Event
+	err      error
+}
+
+// parallel returns true if the projection can handle events in more than one worker, transactions,
+// batches and states are handled in the order the events are fetched
+func (p *Projection) parallel() bool {
+	return p.transactor == nil && p.batchF == nil && !p.sequential
+}
+
+// workerKey returns the key used to shard the event between the workers
+func (p *Projection) workerKey(event Event) string {
+	if p.KeyFunc != nil {
+		return p.KeyFunc(event)
+	}
+	return event.AggregateID()
+}
+
+// iterateParallel handles the events in p.Workers goroutines. Events with the same key are handled by the
+// same worker in the order they are fetched.
+func (p *Projection) iterateParallel(ctx context.Context, iterator *Iterator) (bool, ProjectionResult) {
+	var ran bool
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := watermark{}
+	wg := sync.WaitGroup{}
+	workers := make([]chan *slot, p.Workers)
+	for i := range workers {
+		workers[i] = make(chan *slot, 1)
+		wg.Add(1)
+		go func(slots chan *slot) {
+			defer wg.Done()
+			for s := range slots {
+				// skip the remaining events when an event has failed
+				if ctx.Err() != nil {
+					continue
+				}
+				err := p.handle(ctx, s.event)
+				if err != nil {
+					w.fail(err)
+					cancel()
+					continue
+				}
+				err = w.complete(s, p.handled)
+				if err != nil {
+					cancel()
+				}
+			}
+		}(workers[i])
+	}
+
+	var err error
+	for iterator.Next() {
+		if ctx.Err() != nil {
+			break
+		}
+		ran = true
+		var event Event
+		event, err = iterator.Value()
+		if err != nil {
+			if errors.Is(err, ErrEventNotRegistered) && !p.Strict {
+				err = nil
+				continue
+			}
+			break
+		}
+		s := &slot{event: event}
+		w.dispatch(s)
+
+		h := fnv.New32a()
+		h.Write([]byte(p.workerKey(event)))
+		workers[h.Sum32()%uint32(p.Workers)] <- s
+	}
+	for _, slots := range workers {
+		close(slots)
+	}
+	wg.Wait()
+
+	if err == nil {
+		err = w.err
+	}
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		// events after the watermark could have been handled, runOnce continues from the watermark on next run
+		return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: w.lastDone}
+	}
+	p.scan(iterator.CoreIterator)
+	return ran, ProjectionResult{Error: nil, Name: p.Name, LastHandledEvent: w.lastDone}
+}
+
+func (w *watermark) dispatch(s *slot) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.slots = append(w.slots, s)
+}
+
+func (w *watermark) fail(err error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// complete marks the slot as done and moves the watermark over the completed events in global version order
+func (w *watermark) complete(s *slot, handled func(event Event) error) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	s.done = true
+	// a failed event is never done which stops the watermark before it
+	for len(w.slots) > 0 && w.slots[0].done {
+		event := w.slots[0].event
+		w.slots = w.slots[1:]
+		err := handled(event)
+		if err != nil {
+			w.err = err
+			return err
+		}
+		w.lastDone = event
+	}
+	return nil
+}
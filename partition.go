@@ -0,0 +1,196 @@
+package eventsourcing
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/fetcher"
+)
+
+// PartitionedProjection splits the events of a projection into partitions on a hash of the aggregate id.
+// The partitions are spread over the live members of the projection, that can run in different processes,
+// and are moved between the members when a member joins or leaves. Each partition is a checkpoint projection
+// storing its own checkpoint and holding a lease on its name while it runs.
+type PartitionedProjection struct {
+	name        string
+	partitions  int
+	checkpoints core.CheckpointStore
+	fetchFrom   core.FetcherFrom
+	callbackF   contextCallbackFunc
+	running     atomic.Bool
+	group       *ProjectionGroup
+	assigned    map[int]*Projection
+	lock        sync.Mutex
+	// Coordinator keeps track of the live members and holds the leases on the partitions
+	Coordinator core.Coordinator
+	// Member identifies the process, ex. the host name. It has to be unique among the members.
+	Member string
+	// TTL is how long a member is live without a heartbeat, default 15 seconds
+	TTL time.Duration
+	// Heartbeat is how often the member sends a heartbeat and rebalances the partitions, default a third of the TTL
+	Heartbeat time.Duration
+	// Setup is called on each partition projection when it's created, used to set its properties
+	Setup func(p *Projection)
+}
+
+// NewPartitionedProjection creates a projection where the events are split into the number of partitions
+func NewPartitionedProjection(name string, partitions int, cs core.CheckpointStore, fetchFrom core.FetcherFrom, callbackF contextCallbackFunc) *PartitionedProjection {
+	return &PartitionedProjection{
+		name:        name,
+		partitions:  partitions,
+		checkpoints: cs,
+		fetchFrom:   fetchFrom,
+		callbackF:   callbackF,
+		assigned:    make(map[int]*Projection),
+	}
+}
+
+// PartitionName returns the name of the partition projection, used as the name of its checkpoint and lease
+func (pp *PartitionedProjection) PartitionName(partition int) string {
+	return fmt.Sprintf("%s-%d", pp.name, partition)
+}
+
+// Assigned returns the partitions assigned to the member sorted in order
+func (pp *PartitionedProjection) Assigned() []int {
+	pp.lock.Lock()
+	defer pp.lock.Unlock()
+
+	assigned := make([]int, 0, len(pp.assigned))
+	for partition := range pp.assigned {
+		assigned = append(assigned, partition)
+	}
+	sort.Ints(assigned)
+	return assigned
+}
+
+// Status returns a snapshot of the state of the partition projections run by the member
+func (pp *PartitionedProjection) Status() []ProjectionStatus {
+	pp.lock.Lock()
+	group := pp.group
+	pp.lock.Unlock()
+	if group == nil {
+		return nil
+	}
+	return group.Status()
+}
+
+// Run joins the members of the projection and runs the partitions assigned to the member until the context
+// is cancelled or a partition returns an error. On return the partitions finish the fetched events and store
+// their checkpoint before the member leaves.
+func (pp *PartitionedProjection) Run(ctx context.Context, pace time.Duration) error {
+	if !pp.running.CompareAndSwap(false, true) {
+		return ErrProjectionAlreadyRunning
+	}
+	defer pp.running.Store(false)
+
+	group := NewProjectionGroup()
+	group.Pace = pace
+	group.Start()
+	pp.lock.Lock()
+	pp.group = group
+	pp.lock.Unlock()
+
+	defer func() {
+		group.Shutdown(context.Background())
+		pp.Coordinator.Leave(pp.name, pp.Member)
+		pp.lock.Lock()
+		clear(pp.assigned)
+		pp.lock.Unlock()
+	}()
+
+	for {
+		err := pp.rebalance(ctx, group)
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-group.ErrChan:
+			return err
+		case <-time.After(pp.heartbeat()):
+		}
+	}
+}
+
+// rebalance sends a heartbeat and starts the partitions assigned to the member and stops the ones that are not.
+// The partitions are assigned round robin over the live members sorted by name.
+func (pp *PartitionedProjection) rebalance(ctx context.Context, group *ProjectionGroup) error {
+	members, err := pp.Coordinator.Heartbeat(ctx, pp.name, pp.Member, pp.ttl())
+	if err != nil {
+		return err
+	}
+	owned := make(map[int]bool)
+	for partition := 0; partition < pp.partitions && len(members) > 0; partition++ {
+		if members[partition%len(members)] == pp.Member {
+			owned[partition] = true
+		}
+	}
+
+	pp.lock.Lock()
+	var revoked []*Projection
+	for partition, projection := range pp.assigned {
+		if !owned[partition] {
+			revoked = append(revoked, projection)
+			delete(pp.assigned, partition)
+		}
+	}
+	var added []*Projection
+	for partition := range owned {
+		if _, ok := pp.assigned[partition]; !ok {
+			projection := pp.partition(partition)
+			pp.assigned[partition] = projection
+			added = append(added, projection)
+		}
+	}
+	pp.lock.Unlock()
+
+	// the revoked partitions store their checkpoint and release the lease before the new owner can start them
+	for _, projection := range revoked {
+		group.Remove(projection)
+	}
+	group.Add(added...)
+	return nil
+}
+
+// partition creates the checkpoint projection handling the events in the partition
+func (pp *PartitionedProjection) partition(partition int) *Projection {
+	fetchFrom := func(start core.Version) core.Fetcher {
+		return fetcher.Filter(pp.fetchFrom(start), func(event core.Event) bool {
+			return partitionOf(event.AggregateID, pp.partitions) == partition
+		})
+	}
+	projection := NewCheckpointProjectionContext(pp.PartitionName(partition), pp.checkpoints, fetchFrom, pp.callbackF)
+	projection.Lease = &Lease{Store: pp.Coordinator, Owner: pp.Member, TTL: pp.TTL, Renew: pp.Heartbeat}
+	if pp.Setup != nil {
+		pp.Setup(projection)
+	}
+	return projection
+}
+
+func (pp *PartitionedProjection) ttl() time.Duration {
+	if pp.TTL > 0 {
+		return pp.TTL
+	}
+	return time.Second * 15
+}
+
+func (pp *PartitionedProjection) heartbeat() time.Duration {
+	if pp.Heartbeat > 0 {
+		return pp.Heartbeat
+	}
+	return pp.ttl() / 3
+}
+
+// partitionOf returns the partition of the aggregate id
+func partitionOf(id string, partitions int) int {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return int(h.Sum32() % uint32(partitions))
+}
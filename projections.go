@@ -3,6 +3,7 @@ package eventsourcing
 import (
 	"context"
 	"errors"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -12,22 +13,88 @@ import (
 
 type callbackFunc func(e Event) error
 
+type contextCallbackFunc func(ctx context.Context, e Event) error
+
 // ErrProjectionAlreadyRunning is returned if Run is called on an already running projection
 var ErrProjectionAlreadyRunning = errors.New("projection is already running")
 
+// ErrWorkersNotSupported is returned when a projection that handles events sequentially is run with more than one worker
+var ErrWorkersNotSupported = errors.New("projection can't handle events in more than one worker")
+
 type Projection struct {
-	running   atomic.Bool
-	fetchF    core.Fetcher
-	callbackF callbackFunc
-	trigger   chan func()
-	Strict    bool // Strict indicate if the projection should return error if the event it fetches is not found in the register
-	Name      string
+	running      atomic.Bool
+	fetchF       core.Fetcher
+	fetchFrom    core.FetcherFrom
+	callbackF    contextCallbackFunc
+	trigger      chan func()
+	checkpoints  core.CheckpointStore
+	pending      core.Version // global version of the last handled event not yet stored in the checkpoint store
+	unsaved      uint64       // number of handled events since the checkpoint was stored
+	savedAt      time.Time    // when the checkpoint was stored
+	lastHandled  Event        // last handled event, committed or not
+	scanned      core.Version // global version of the last fetched event, including the events skipped by the fetcher
+	transactor   Transactor   // set on transactional projections
+	sequential   bool         // set on projections that must handle the events in order, like state projections
+	tx           Transaction  // the open transaction on a transactional projection
+	committed    Event        // last event committed by a transactional projection
+	batchF       batchCallbackFunc
+	batch        []Event         // events collected by a batch projection
+	batchStart   time.Time       // when the first event in the batch was collected
+	stop         <-chan struct{} // closed when a running projection should stop
+	position     atomic.Uint64   // global version of the last handled event
+	handledAt    atomic.Int64    // timestamp in unix nano of the last handled event
+	advanced     chan struct{}   // closed when the position is advanced
+	advancedLock sync.Mutex
+	shared       atomic.Pointer[sharedReader]    // set when the projection runs in a group with a shared reader
+	graph        atomic.Pointer[dependencyGraph] // set when the projection runs in a group
+	paused       atomic.Bool
+	exec         sync.Mutex // held while the projection runs, seek waits for the fetched events to be handled
+	Strict       bool       // Strict indicate if the projection should return error if the event it fetches is not found in the register
+	Name         string
+	// CheckpointEvery store the checkpoint every N handled events, 0 or 1 stores it after each event
+	CheckpointEvery uint64
+	// CheckpointInterval store the checkpoint at most once per interval, if set CheckpointEvery is ignored
+	CheckpointInterval time.Duration
+	// EventTimeout is the deadline set on the context passed to the callback for each event
+	EventTimeout time.Duration
+	// OnError is the policy applied when the callback returns an error, default the projection halts
+	OnError ErrorPolicy
+	// Workers is the number of goroutines handling events concurrently, events with the same key are
+	// handled in order by the same worker. Default 0 handles the events sequentially. Transactional, batch
+	// and state projections handle events sequentially and return ErrWorkersNotSupported if it's set.
+	Workers int
+	// KeyFunc returns the key used to distribute events between the workers, default the aggregate id
+	KeyFunc func(e Event) string
+	// BatchSize is the max number of events handled in each call to a batch callback
+	BatchSize int
+	// BatchLinger is how long a running projection waits for a batch to fill up before it's handled
+	BatchLinger time.Duration
+	// DryRun runs the callback without storing the checkpoint, transactions are rolled back instead of committed
+	DryRun bool
+	// Restart is the policy applied by a group when the projection returns an error, default it's not restarted
+	Restart RestartPolicy
+	// Head returns the global version of the latest event in the event store, used to calculate the lag
+	Head core.HeadFunc
+	// Lease makes the projection run only in the process holding the lease on the projection name
+	Lease *Lease
 }
 
 // ProjectionGroup runs projections concurrently
 type ProjectionGroup struct {
-	Pace        time.Duration // Pace is used when a projection is running and it reaches the end of the event stream
+	Pace time.Duration // Pace is used when a projection is running and it reaches the end of the event stream
+	// Reader reads the event stream once for the checkpoint projections in the group instead of each projection
+	// fetching the events, the projections must read the same event stream. Default each projection fetch its events.
+	Reader core.FetcherFrom
+	// Buffer is the max number of events read by the shared reader that a projection has not handled, a projection
+	// with a full buffer fetch the events from the event store until it has caught up. Default 1000.
+	Buffer      int
+	shared      *sharedReader
+	readerDone  chan struct{}
+	graph       *dependencyGraph
 	projections []*Projection
+	members     map[*Projection]*member
+	lock        sync.Mutex
+	ctx         context.Context
 	cancelF     context.CancelFunc
 	wg          sync.WaitGroup
 	ErrChan     chan error
@@ -42,15 +109,45 @@ type ProjectionResult struct {
 
 // Projection creates a projection that will run down an event stream
 func NewProjection(fetchF core.Fetcher, callbackF callbackFunc) *Projection {
+	return NewProjectionContext(fetchF, withoutContext(callbackF))
+}
+
+// NewProjectionContext creates a projection where the callback receives the context the projection
+// is running with. The context has a deadline if the projection property EventTimeout is set.
+func NewProjectionContext(fetchF core.Fetcher, callbackF contextCallbackFunc) *Projection {
 	projection := Projection{
 		fetchF:    fetchF,
 		callbackF: callbackF,
-		trigger:   make(chan func()),
+		trigger:   make(chan func(), 1),
 		Strict:    true, // Default strict is active
 	}
 	return &projection
 }
 
+// NewCheckpointProjection creates a projection that resumes from the checkpoint stored on its name in
+// the checkpoint store. The fetcher is created from the global version after the checkpoint and the global
+// version of the handled events is stored in the checkpoint store as the projection runs.
+func NewCheckpointProjection(name string, cs core.CheckpointStore, fetchFrom core.FetcherFrom, callbackF callbackFunc) *Projection {
+	return NewCheckpointProjectionContext(name, cs, fetchFrom, withoutContext(callbackF))
+}
+
+// NewCheckpointProjectionContext creates a checkpoint projection where the callback receives the context
+// the projection is running with.
+func NewCheckpointProjectionContext(name string, cs core.CheckpointStore, fetchFrom core.FetcherFrom, callbackF contextCallbackFunc) *Projection {
+	projection := NewProjectionContext(nil, callbackF)
+	projection.Name = name
+	projection.checkpoints = cs
+	projection.fetchFrom = fetchFrom
+	return projection
+}
+
+// withoutContext wraps a callback that does not use the context
+func withoutContext(callbackF callbackFunc) contextCallbackFunc {
+	return func(ctx context.Context, e Event) error {
+		return callbackF(e)
+	}
+}
+
 // TriggerAsync force a running projection to run immediately independent on the pace
 // It will return immediately after triggering the prjection to run.
 // If the trigger channel is already filled it will return without inserting any value.
@@ -70,6 +167,10 @@ func (p *Projection) TriggerSync() {
 	if !p.running.Load() {
 		return
 	}
+	// read the latest events for the projection
+	if r := p.shared.Load(); r != nil {
+		r.readToEnd(context.Background())
+	}
 	wg := sync.WaitGroup{}
 	wg.Add(1)
 	f := func() {
@@ -79,17 +180,60 @@ func (p *Projection) TriggerSync() {
 	wg.Wait()
 }
 
+// WaitFor blocks until the projection has handled the event with the global version or the context is done.
+// An event skipped by the fetcher is passed when the events fetched before it are handled. The running projection
+// is triggered to fetch the events instead of waiting for the pace.
+func (p *Projection) WaitFor(ctx context.Context, version Version) error {
+	for {
+		p.advancedLock.Lock()
+		if p.advanced == nil {
+			p.advanced = make(chan struct{})
+		}
+		advanced := p.advanced
+		p.advancedLock.Unlock()
+
+		if Version(p.position.Load()) >= version {
+			return nil
+		}
+		if r := p.shared.Load(); r != nil {
+			r.trigger()
+		}
+		p.TriggerAsync()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-advanced:
+		}
+	}
+}
+
 // Run runs the projection forever until the context is cancelled. When there are no more events to consume it
 // waits for a trigger or context cancel.
 func (p *Projection) Run(ctx context.Context, pace time.Duration) error {
+	return p.run(ctx, pace, nil)
+}
+
+// run runs the projection until the context is cancelled or the stop channel is closed. When stopped the
+// projection finish the fetched events and stores the checkpoint before it returns.
+func (p *Projection) run(ctx context.Context, pace time.Duration, stop <-chan struct{}) error {
 	if p.running.Load() {
 		return ErrProjectionAlreadyRunning
 	}
 	p.running.Store(true)
+	p.stop = stop
 	defer func() {
 		p.running.Store(false)
+		p.stop = nil
 	}()
 
+	if p.Lease != nil {
+		return p.runLeased(ctx, pace, stop)
+	}
+	return p.loop(ctx, pace, stop)
+}
+
+// loop runs the projection to the end of the event stream and waits for the pace or a trigger to run again
+func (p *Projection) loop(ctx context.Context, pace time.Duration, stop <-chan struct{}) error {
 	var noopFunc = func() {}
 	var f = noopFunc
 	triggerFunc := func() {
@@ -98,17 +242,31 @@ func (p *Projection) Run(ctx context.Context, pace time.Duration) error {
 		f = noopFunc
 	}
 	for {
-		result := p.RunToEnd(ctx)
+		wait := pace
+		// a paused projection does not fetch events but sync triggers are released
+		if !p.Paused() {
+			p.exec.Lock()
+			// events in a batch are kept until the batch is full or the linger time has passed, or the projection is paused
+			result := p.runToEndAndSave(ctx, p.BatchLinger == 0 || p.Paused())
+			wait = p.wait(pace)
+			p.exec.Unlock()
+			if result.Error != nil {
+				triggerFunc()
+				return result.Error
+			}
+		}
 		// if triggered by a sync trigger the triggerFunc callback that it's finished
 		// if not triggered by a sync trigger the triggerFunc will call an no ops function
 		triggerFunc()
-		if result.Error != nil {
-			return result.Error
+		if p.stopped() {
+			return nil
 		}
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-time.After(pace):
+		case <-stop:
+			// run a last time to handle the collected events and store the checkpoint
+		case <-time.After(wait):
 		case f = <-p.trigger:
 		}
 	}
@@ -116,6 +274,33 @@ func (p *Projection) Run(ctx context.Context, pace time.Duration) error {
 
 // RunToEnd runs until the projection reaches the end of the event stream
 func (p *Projection) RunToEnd(ctx context.Context) ProjectionResult {
+	return p.runToEndAndSave(ctx, true)
+}
+
+// runToEndAndSave runs to the end of the event stream and stores the checkpoint, if drain is true
+// the events collected in a batch are handled even if the batch is not due.
+func (p *Projection) runToEndAndSave(ctx context.Context, drain bool) ProjectionResult {
+	result := p.runToEnd(ctx)
+	if drain || p.stopped() {
+		result = p.drain(ctx, result)
+	}
+	// store the position of the events handled since the last stored checkpoint
+	err := p.saveCheckpoint()
+	if err != nil && result.Error == nil {
+		result.Error = err
+	}
+	if err != nil && p.transactor != nil {
+		p.rollback()
+		result.LastHandledEvent = p.committed
+	}
+	// the events skipped by the fetcher after the last handled event are passed when all fetched events are handled
+	if result.Error == nil && len(p.batch) == 0 && p.tx == nil && Version(p.scanned) > Version(p.position.Load()) {
+		p.advance(Version(p.scanned))
+	}
+	return result
+}
+
+func (p *Projection) runToEnd(ctx context.Context) ProjectionResult {
 	var result ProjectionResult
 	var lastHandledEvent Event
 
@@ -124,7 +309,7 @@ func (p *Projection) RunToEnd(ctx context.Context) ProjectionResult {
 		case <-ctx.Done():
 			return ProjectionResult{Error: ctx.Err(), Name: result.Name, LastHandledEvent: result.LastHandledEvent}
 		default:
-			ran, result := p.RunOnce()
+			ran, result := p.runOnce(ctx)
 			// if the first event returned error or if it did not run at all
 			if result.LastHandledEvent.GlobalVersion() == 0 {
 				result.LastHandledEvent = lastHandledEvent
@@ -132,8 +317,8 @@ func (p *Projection) RunToEnd(ctx context.Context) ProjectionResult {
 			if result.Error != nil {
 				return result
 			}
-			// hit the end of the event stream
-			if !ran {
+			// hit the end of the event stream or the projection is stopping or paused
+			if !ran || p.stopped() || p.Paused() {
 				return result
 			}
 			lastHandledEvent = result.LastHandledEvent
@@ -143,19 +328,88 @@ func (p *Projection) RunToEnd(ctx context.Context) ProjectionResult {
 
 // RunOnce runs the fetch method one time
 func (p *Projection) RunOnce() (bool, ProjectionResult) {
+	ran, result := p.runOnce(context.Background())
+	// a batch projection without linger time handles the collected events on each run
+	if p.BatchLinger == 0 {
+		result = p.drain(context.Background(), result)
+		if result.Error != nil {
+			ran = false
+		}
+	}
+	return ran, result
+}
+
+// stopped returns true if the running projection is asked to stop
+func (p *Projection) stopped() bool {
+	select {
+	case <-p.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *Projection) runOnce(ctx context.Context) (bool, ProjectionResult) {
+	ran, result := p.iterate(ctx)
+	// the position of a transactional projection is set when the transaction is committed
+	if p.transactor == nil {
+		p.setPosition(result.LastHandledEvent)
+	}
+	// transactions are committed when the iterator is closed
+	if result.Error == nil && p.transactor != nil && p.checkpointDue() {
+		err := p.saveCheckpoint()
+		if err != nil {
+			ran, result = false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: result.LastHandledEvent}
+		}
+	}
+	if result.Error != nil && p.transactor != nil {
+		// the events handled since the last commit are discarded
+		p.rollback()
+		result.LastHandledEvent = p.committed
+	} else if result.Error != nil && p.fetchFrom != nil && p.fetchF != nil {
+		// the events after the last handled event could have been fetched, continue after it on next run
+		p.fetchF = p.from(p.pending + 1)
+	}
+	return ran, result
+}
+
+func (p *Projection) iterate(ctx context.Context) (bool, ProjectionResult) {
 	// ran indicate if there were events to fetch
 	var ran bool
+	// due indicate if the iteration stopped to commit the transaction
+	var due bool
 	var lastHandledEvent Event
 
-	coreIterator, err := p.fetchF()
+	if p.Workers > 1 && !p.parallel() {
+		return false, ProjectionResult{Error: ErrWorkersNotSupported, Name: p.Name, LastHandledEvent: lastHandledEvent}
+	}
+	fetchF, err := p.fetcher(ctx)
+	if err != nil {
+		return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: lastHandledEvent}
+	}
+	coreIterator, err := fetchF()
 	if err != nil {
 		return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: lastHandledEvent}
 	}
+	// a projection held back by upstream projections fetch the first event after the ceiling again next time
+	if ceiling, ok := p.ceiling(); ok {
+		bound := &boundIterator{iterator: coreIterator, ceiling: ceiling}
+		coreIterator = bound
+		defer func() {
+			if bound.held != 0 {
+				p.fetchF = p.from(bound.held)
+			}
+		}()
+	}
 	iterator := &Iterator{
 		CoreIterator: coreIterator,
 	}
 	defer iterator.Close()
 
+	if p.Workers > 1 {
+		return p.iterateParallel(ctx, iterator)
+	}
+
 	for iterator.Next() {
 		ran = true
 		event, err := iterator.Value()
@@ -169,47 +423,263 @@ func (p *Projection) RunOnce() (bool, ProjectionResult) {
 			return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: lastHandledEvent}
 		}
 
-		err = p.callbackF(event)
+		if p.batchF != nil {
+			last, err := p.collect(ctx, event)
+			if last.GlobalVersion() != 0 {
+				lastHandledEvent = last
+			}
+			if err != nil {
+				return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: lastHandledEvent}
+			}
+			continue
+		}
+
+		err = p.handle(ctx, event)
 		if err != nil {
 			return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: lastHandledEvent}
 		}
 		// keep a reference to the last successfully handled event
 		lastHandledEvent = event
+
+		err = p.handled(event)
+		if err != nil {
+			return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: lastHandledEvent}
+		}
+		// stop iterating when the transaction is due to be committed
+		if p.transactor != nil && p.checkpointDue() {
+			due = true
+			break
+		}
+	}
+	if !due {
+		p.scan(coreIterator)
+	}
+	if p.batchDue() {
+		last, err := p.flush(ctx)
+		if last.GlobalVersion() != 0 {
+			lastHandledEvent = last
+		}
+		if err != nil {
+			return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: lastHandledEvent}
+		}
 	}
 	return ran, ProjectionResult{Error: nil, Name: p.Name, LastHandledEvent: lastHandledEvent}
 }
 
+// scan keeps the position of an iterator that skips events, it's called when the iterator has reached its end
+func (p *Projection) scan(iterator core.Iterator) {
+	if position := core.IteratorPosition(iterator, 0); position > p.scanned {
+		p.scanned = position
+	}
+}
+
+// fetcher returns the fetcher, if the projection is based on a checkpoint the fetcher is created
+// from the global version after the stored checkpoint the first time it's called.
+func (p *Projection) fetcher(ctx context.Context) (core.Fetcher, error) {
+	if p.fetchF != nil {
+		return p.fetchF, nil
+	}
+	version, err := p.checkpoints.Load(ctx, p.Name)
+	if err != nil && !errors.Is(err, core.ErrCheckpointNotFound) {
+		return nil, err
+	}
+	p.pending = version
+	p.advance(Version(version))
+	p.fetchF = p.from(version + 1)
+	return p.fetchF, nil
+}
+
+// handled keeps track of the handled event and stores the checkpoint when it's due
+func (p *Projection) handled(event Event) error {
+	if p.checkpoints == nil {
+		return nil
+	}
+	p.track(event, 1)
+
+	// transactions are committed by RunOnce
+	if p.transactor != nil || !p.checkpointDue() {
+		return nil
+	}
+	return p.saveCheckpoint()
+}
+
+// setPosition keeps the global version of the last handled event
+func (p *Projection) setPosition(event Event) {
+	if event.GlobalVersion() != 0 {
+		p.handledAt.Store(event.Timestamp().UnixNano())
+		p.advance(event.GlobalVersion())
+	}
+}
+
+// advance sets the position and notifies the callers waiting for the projection to reach a version
+func (p *Projection) advance(version Version) {
+	p.position.Store(uint64(version))
+	p.advancedLock.Lock()
+	defer p.advancedLock.Unlock()
+	if p.advanced != nil {
+		close(p.advanced)
+		p.advanced = nil
+	}
+	// trigger the projections held back by the projection
+	if graph := p.graph.Load(); graph != nil {
+		graph.advanced(p)
+	}
+}
+
+// track keeps the last handled event as the pending checkpoint
+func (p *Projection) track(event Event, handled uint64) {
+	p.pending = core.Version(event.GlobalVersion())
+	p.lastHandled = event
+	p.unsaved += handled
+}
+
+// checkpointDue returns true if there are handled events and the checkpoint should be stored
+func (p *Projection) checkpointDue() bool {
+	if p.unsaved == 0 {
+		return false
+	}
+	if p.CheckpointInterval > 0 {
+		return time.Since(p.savedAt) >= p.CheckpointInterval
+	}
+	return p.unsaved >= p.CheckpointEvery
+}
+
+// saveCheckpoint stores the global version of the last handled event in the checkpoint store
+func (p *Projection) saveCheckpoint() error {
+	if p.checkpoints == nil || p.unsaved == 0 {
+		return nil
+	}
+	if p.DryRun {
+		p.discard()
+		return nil
+	}
+	if p.transactor != nil {
+		return p.commit()
+	}
+	err := p.checkpoints.Save(p.Name, p.pending)
+	if err != nil {
+		return err
+	}
+	p.unsaved = 0
+	p.savedAt = time.Now()
+	return nil
+}
+
 // Group runs a group of projections concurrently
 func NewProjectionGroup(projections ...*Projection) *ProjectionGroup {
 	return &ProjectionGroup{
 		projections: projections,
 		cancelF:     func() {},
+		graph:       newDependencyGraph(),
 		Pace:        time.Second * 10, // Default pace 10 seconds
 	}
 }
 
 // Start starts all projectinos in the group, an error channel i created on the group to notify
-// if a result containing an error is returned from a projection
+// if a result containing an error is returned from a projection that is not restarted
 func (g *ProjectionGroup) Start() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
 	g.ErrChan = make(chan error)
-	ctx, cancel := context.WithCancel(context.Background())
-	g.cancelF = cancel
+	g.ctx, g.cancelF = context.WithCancel(context.Background())
+	g.members = make(map[*Projection]*member)
+	g.shared = nil
+	if g.Reader != nil {
+		g.shared = newSharedReader(g.Reader, g.Buffer)
+		g.readerDone = make(chan struct{})
+		go func(ctx context.Context, shared *sharedReader, done chan struct{}) {
+			defer close(done)
+			shared.run(ctx, g.Pace)
+		}(g.ctx, g.shared, g.readerDone)
+	}
+	for _, projection := range g.projections {
+		g.start(projection)
+	}
+}
+
+// start runs the projection in a separate go routine supervised by the group
+func (g *ProjectionGroup) start(projection *Projection) {
+	ctx, cancel := context.WithCancel(g.ctx)
+	m := &member{
+		projection: projection,
+		cancel:     cancel,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+		state:      ProjectionRunning,
+	}
+	g.members[projection] = m
+	g.graph.add(projection)
+	projection.graph.Store(g.graph)
+	if g.shared != nil && projection.fetchFrom != nil {
+		projection.shared.Store(g.shared)
+	}
+	g.wg.Add(1)
+	go g.supervise(ctx, m, g.ErrChan)
+}
 
-	g.wg.Add(len(g.projections))
+// Add adds projections to the group, if the group is started the projections are started directly.
+// A projection that is already in the group is not added again.
+func (g *ProjectionGroup) Add(projections ...*Projection) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	for _, projection := range projections {
+		if slices.Contains(g.projections, projection) {
+			continue
+		}
+		g.projections = append(g.projections, projection)
+		// the group is started
+		if g.ErrChan != nil {
+			g.start(projection)
+		}
+	}
+}
+
+// Remove removes a projection from the group. If the group is started the projection finish the
+// fetched events and stores its checkpoint before Remove returns.
+func (g *ProjectionGroup) Remove(projection *Projection) {
+	g.lock.Lock()
+	g.projections = slices.DeleteFunc(g.projections, func(p *Projection) bool {
+		return p == projection
+	})
+	m := g.members[projection]
+	delete(g.members, projection)
+	g.graph.remove(projection)
+	g.lock.Unlock()
+
+	if m != nil {
+		m.drain()
+		<-m.done
+	}
+}
+
+// Status returns a snapshot of the state of each projection in the group
+func (g *ProjectionGroup) Status() []ProjectionStatus {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	status := make([]ProjectionStatus, 0, len(g.projections))
 	for _, projection := range g.projections {
-		go func(p *Projection) {
-			defer g.wg.Done()
-			err := p.Run(ctx, g.Pace)
-			if !errors.Is(err, context.Canceled) {
-				g.ErrChan <- err
-			}
-		}(projection)
+		if m, ok := g.members[projection]; ok {
+			status = append(status, m.status())
+			continue
+		}
+		status = append(status, ProjectionStatus{
+			Name:                     projection.Name,
+			State:                    ProjectionStopped,
+			LastHandledGlobalVersion: Version(projection.position.Load()),
+		})
 	}
+	return status
 }
 
 // TriggerAsync force all projections to run not waiting for them to finish
 func (g *ProjectionGroup) TriggerAsync() {
-	for _, projection := range g.projections {
+	for _, projection := range g.list() {
+		if r := projection.shared.Load(); r != nil {
+			r.trigger()
+		}
 		projection.TriggerAsync()
 	}
 }
@@ -217,7 +687,7 @@ func (g *ProjectionGroup) TriggerAsync() {
 // TriggerSync force all projections to run and wait for them to finish
 func (g *ProjectionGroup) TriggerSync() {
 	wg := sync.WaitGroup{}
-	for _, projection := range g.projections {
+	for _, projection := range g.list() {
 		wg.Add(1)
 		go func(p *Projection) {
 			p.TriggerSync()
@@ -227,8 +697,40 @@ func (g *ProjectionGroup) TriggerSync() {
 	wg.Wait()
 }
 
+// WaitFor blocks until all projections in the group has handled the event with the global version or the
+// context is done
+func (g *ProjectionGroup) WaitFor(ctx context.Context, version Version) error {
+	var lock sync.Mutex
+	var causingErr error
+
+	wg := sync.WaitGroup{}
+	for _, projection := range g.list() {
+		wg.Add(1)
+		go func(p *Projection) {
+			defer wg.Done()
+			err := p.WaitFor(ctx, version)
+			if err != nil {
+				lock.Lock()
+				causingErr = err
+				lock.Unlock()
+			}
+		}(projection)
+	}
+	wg.Wait()
+	return causingErr
+}
+
+// list returns a copy of the projections in the group
+func (g *ProjectionGroup) list() []*Projection {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return slices.Clone(g.projections)
+}
+
 // Stop halts all projections in the group
 func (g *ProjectionGroup) Stop() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
 	if g.ErrChan == nil {
 		return
 	}
@@ -236,6 +738,10 @@ func (g *ProjectionGroup) Stop() {
 
 	// return when all projections has stopped
 	g.wg.Wait()
+	if g.readerDone != nil {
+		<-g.readerDone
+		g.readerDone = nil
+	}
 
 	// close the error channel
 	close(g.ErrChan)
@@ -243,6 +749,32 @@ func (g *ProjectionGroup) Stop() {
 	g.ErrChan = nil
 }
 
+// Shutdown stops the projections in the group gracefully. Each projection finish the fetched events and stores
+// its checkpoint before it stops. If the context is done before all projections have stopped they are halted
+// and the context error is returned.
+func (g *ProjectionGroup) Shutdown(ctx context.Context) error {
+	g.lock.Lock()
+	for _, m := range g.members {
+		m.drain()
+	}
+	g.lock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	g.Stop()
+	return err
+}
+
 // ProjectionsRace runs the projections to the end of the events streams.
 // Can be used on a stale event stream with no more events coming in or when you want to know when all projections are done.
 func ProjectionsRace(cancelOnError bool, projections ...*Projection) ([]ProjectionResult, error) {
@@ -4,15 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/r23vme/eventsourcing"
 	"github.com/r23vme/eventsourcing/aggregate"
+	csmemory "github.com/r23vme/eventsourcing/checkpointstore/memory"
 	"github.com/r23vme/eventsourcing/core"
+	dlmemory "github.com/r23vme/eventsourcing/deadletterstore/memory"
 	"github.com/r23vme/eventsourcing/eventstore/memory"
+	"github.com/r23vme/eventsourcing/fetcher"
 	"github.com/r23vme/eventsourcing/internal"
+	lsmemory "github.com/r23vme/eventsourcing/leasestore/memory"
+	ssmemory "github.com/r23vme/eventsourcing/snapshotstore/memory"
 )
 
 // Person aggregate
@@ -503,3 +510,2338 @@ func TestKeepStartPosition(t *testing.T) {
 		t.Fatalf("expected counter to be 10 was %d", counter)
 	}
 }
+
+func TestCheckpointResume(t *testing.T) {
+	// setup
+	es := memory.Create()
+	cs := csmemory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetchFrom := func(start core.Version) core.Fetcher {
+		return es.All(start, 2)
+	}
+
+	counter := 0
+	callbackF := func(event eventsourcing.Event) error {
+		counter++
+		return nil
+	}
+
+	p := eventsourcing.NewCheckpointProjection("persons", cs, fetchFrom, callbackF)
+	result := p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	checkpoint, err := cs.Load(context.Background(), "persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint != 6 {
+		t.Fatalf("expected checkpoint to be 6 was %d", checkpoint)
+	}
+
+	err = createPersonEvent(es, "anka", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a new projection with the same name should resume after the stored checkpoint
+	p2 := eventsourcing.NewCheckpointProjection("persons", cs, fetchFrom, callbackF)
+	result = p2.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	// Born 1 + AgedOneYear 5 + Born 1 + AgedOneYear 2 = 9
+	if counter != 9 {
+		t.Fatalf("expected counter to be 9 was %d", counter)
+	}
+	checkpoint, err = cs.Load(context.Background(), "persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint != 9 {
+		t.Fatalf("expected checkpoint to be 9 was %d", checkpoint)
+	}
+}
+
+func TestCheckpointEvery(t *testing.T) {
+	// setup
+	es := memory.Create()
+	cs := csmemory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := eventsourcing.NewCheckpointProjection("persons", cs, func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	}, func(event eventsourcing.Event) error {
+		return nil
+	})
+	p.CheckpointEvery = 2
+
+	_, result := p.RunOnce()
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	// five events handled the checkpoint is stored on every second event
+	checkpoint, err := cs.Load(context.Background(), "persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint != 4 {
+		t.Fatalf("expected checkpoint to be 4 was %d", checkpoint)
+	}
+
+	// running to the end stores the checkpoint of the last handled event
+	result = p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	checkpoint, err = cs.Load(context.Background(), "persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint != 5 {
+		t.Fatalf("expected checkpoint to be 5 was %d", checkpoint)
+	}
+}
+
+// transactor is a test transactor that keeps the handled events in memory until they are committed
+type transactor struct {
+	*csmemory.Memory
+	committed []eventsourcing.Event
+	failOn    eventsourcing.Version
+}
+
+type transaction struct {
+	t      *transactor
+	events []eventsourcing.Event
+}
+
+func (t *transactor) Begin(ctx context.Context) (eventsourcing.Transaction, error) {
+	return &transaction{t: t}, nil
+}
+
+func (tx *transaction) Handle(event eventsourcing.Event) error {
+	if event.GlobalVersion() == tx.t.failOn {
+		return errors.New("handle error")
+	}
+	tx.events = append(tx.events, event)
+	return nil
+}
+
+func (tx *transaction) Commit(name string, checkpoint core.Version) error {
+	tx.t.committed = append(tx.t.committed, tx.events...)
+	return tx.t.Save(name, checkpoint)
+}
+
+func (tx *transaction) Rollback() error {
+	tx.events = nil
+	return nil
+}
+
+func TestTxProjection(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := &transactor{Memory: csmemory.Create(), failOn: 7}
+	p := eventsourcing.NewTxProjection("persons", tr, func(start core.Version) core.Fetcher {
+		return es.All(start, 100)
+	})
+	p.CheckpointEvery = 3
+
+	// the transaction holding event 7 is rolled back
+	result := p.RunToEnd(context.Background())
+	if result.Error == nil {
+		t.Fatal("expected error from the transaction")
+	}
+	if result.LastHandledEvent.GlobalVersion() != 6 {
+		t.Fatalf("expected last handled event to be 6 was %d", result.LastHandledEvent.GlobalVersion())
+	}
+	if len(tr.committed) != 6 {
+		t.Fatalf("expected 6 committed events got %d", len(tr.committed))
+	}
+
+	// the projection resumes from the committed checkpoint
+	tr.failOn = 0
+	result = p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if len(tr.committed) != 10 {
+		t.Fatalf("expected 10 committed events got %d", len(tr.committed))
+	}
+	for i, event := range tr.committed {
+		if event.GlobalVersion() != eventsourcing.Version(i+1) {
+			t.Fatalf("expected event %d to have global version %d was %d", i, i+1, event.GlobalVersion())
+		}
+	}
+	checkpoint, err := tr.Load(context.Background(), "persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint != 10 {
+		t.Fatalf("expected checkpoint to be 10 was %d", checkpoint)
+	}
+}
+
+func TestRetryOnError(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attempts := 0
+	p := eventsourcing.NewProjection(es.All(0, 10), func(event eventsourcing.Event) error {
+		if event.GlobalVersion() == 2 {
+			attempts++
+			if attempts < 3 {
+				return errors.New("temporary error")
+			}
+		}
+		return nil
+	})
+	p.OnError = eventsourcing.ErrorPolicy{Retries: 2, Backoff: time.Millisecond}
+
+	result := p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts was %d", attempts)
+	}
+	if result.LastHandledEvent.GlobalVersion() != 2 {
+		t.Fatalf("expected last handled event to be 2 was %d", result.LastHandledEvent.GlobalVersion())
+	}
+}
+
+func TestDeadLetter(t *testing.T) {
+	// setup
+	es := memory.Create()
+	ds := dlmemory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	poison := true
+	counter := 0
+	p := eventsourcing.NewProjection(es.All(0, 10), func(event eventsourcing.Event) error {
+		if event.GlobalVersion() == 2 && poison {
+			return errors.New("poison event")
+		}
+		counter++
+		return nil
+	})
+	p.Name = "persons"
+	p.OnError = eventsourcing.ErrorPolicy{Retries: 1, DeadLetter: ds}
+
+	// the poison event is skipped
+	result := p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if counter != 3 {
+		t.Fatalf("expected counter to be 3 was %d", counter)
+	}
+
+	deadLetters, err := p.DeadLetters(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deadLetters) != 1 {
+		t.Fatalf("expected one dead letter got %d", len(deadLetters))
+	}
+	if deadLetters[0].Event.GlobalVersion != 2 || deadLetters[0].Attempts != 2 || deadLetters[0].Error != "poison event" {
+		t.Fatalf("unexpected dead letter %#v", deadLetters[0])
+	}
+
+	// replay the dead letter when the poison is gone
+	poison = false
+	handled, err := p.ReplayDeadLetters(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handled != 1 {
+		t.Fatalf("expected one replayed event got %d", handled)
+	}
+	if counter != 4 {
+		t.Fatalf("expected counter to be 4 was %d", counter)
+	}
+	deadLetters, err = p.DeadLetters(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deadLetters) != 0 {
+		t.Fatalf("expected no dead letters got %d", len(deadLetters))
+	}
+}
+
+func TestTxReplayDeadLetters(t *testing.T) {
+	// setup
+	es := memory.Create()
+	ds := dlmemory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iterator, err := es.All(0, 1)()
+	if err != nil {
+		t.Fatal(err)
+	}
+	iterator.Next()
+	event, err := iterator.Value()
+	iterator.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ds.Save(core.DeadLetter{Name: "persons", Event: event, Error: "poison event", Attempts: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := &transactor{Memory: csmemory.Create()}
+	p := eventsourcing.NewTxProjection("persons", tr, func(start core.Version) core.Fetcher {
+		return es.All(start, 100)
+	})
+	p.OnError = eventsourcing.ErrorPolicy{DeadLetter: ds}
+
+	handled, err := p.ReplayDeadLetters(context.Background())
+	if !errors.Is(err, eventsourcing.ErrTxDeadLetters) {
+		t.Fatalf("expected ErrTxDeadLetters got %v", err)
+	}
+	if handled != 0 {
+		t.Fatalf("expected no replayed events got %d", handled)
+	}
+	deadLetters, err := p.DeadLetters(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deadLetters) != 1 {
+		t.Fatalf("expected the dead letter to be kept got %d", len(deadLetters))
+	}
+}
+
+func TestEventTimeout(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := eventsourcing.NewProjectionContext(es.All(0, 10), func(ctx context.Context, event eventsourcing.Event) error {
+		// slow handler that respects the context
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+		return nil
+	})
+	p.EventTimeout = time.Millisecond * 10
+
+	result := p.RunToEnd(context.Background())
+	if !errors.Is(result.Error, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded got %v", result.Error)
+	}
+}
+
+func TestParallelWorkers(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	for _, name := range []string{"kalle", "anka", "musse", "pluto"} {
+		err := createPersonEvent(es, name, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lock := sync.Mutex{}
+	versions := make(map[string][]eventsourcing.Version)
+	p := eventsourcing.NewProjection(es.All(0, 100), func(event eventsourcing.Event) error {
+		time.Sleep(time.Millisecond)
+		lock.Lock()
+		defer lock.Unlock()
+		versions[event.AggregateID()] = append(versions[event.AggregateID()], event.Version())
+		return nil
+	})
+	p.Workers = 3
+
+	result := p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if result.LastHandledEvent.GlobalVersion() != 24 {
+		t.Fatalf("expected last handled event to be 24 was %d", result.LastHandledEvent.GlobalVersion())
+	}
+	if len(versions) != 4 {
+		t.Fatalf("expected events from 4 aggregates got %d", len(versions))
+	}
+	// the events of each aggregate are handled in order
+	for id, v := range versions {
+		for i := range v {
+			if v[i] != eventsourcing.Version(i+1) {
+				t.Fatalf("aggregate %s events out of order %v", id, v)
+			}
+		}
+	}
+}
+
+func TestParallelWorkersError(t *testing.T) {
+	// setup
+	es := memory.Create()
+	cs := csmemory.Create()
+	aggregate.Register(&Person{})
+
+	for _, name := range []string{"kalle", "anka"} {
+		err := createPersonEvent(es, name, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	applicationErr := errors.New("an error")
+	p := eventsourcing.NewCheckpointProjection("persons", cs, func(start core.Version) core.Fetcher {
+		return es.All(start, 100)
+	}, func(event eventsourcing.Event) error {
+		if event.GlobalVersion() == 8 {
+			return applicationErr
+		}
+		return nil
+	})
+	p.Workers = 2
+
+	result := p.RunToEnd(context.Background())
+	if !errors.Is(result.Error, applicationErr) {
+		t.Fatalf("expected applicationErr got %v", result.Error)
+	}
+	// the last handled event is never after the failed event
+	if result.LastHandledEvent.GlobalVersion() >= 8 {
+		t.Fatalf("expected last handled event to be before 8 was %d", result.LastHandledEvent.GlobalVersion())
+	}
+	checkpoint, err := cs.Load(context.Background(), "persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint != core.Version(result.LastHandledEvent.GlobalVersion()) {
+		t.Fatalf("expected checkpoint %d to equal last handled event %d", checkpoint, result.LastHandledEvent.GlobalVersion())
+	}
+}
+
+func TestParallelWorkersNotSupported(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handled := 0
+	batch := eventsourcing.NewBatchProjection(es.All(0, 10), func(ctx context.Context, events []eventsourcing.Event) error {
+		handled += len(events)
+		return nil
+	})
+	tx := eventsourcing.NewTxProjection("persons", &transactor{Memory: csmemory.Create()}, func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	})
+	for _, p := range []*eventsourcing.Projection{batch, tx} {
+		p.Workers = 2
+		result := p.RunToEnd(context.Background())
+		if !errors.Is(result.Error, eventsourcing.ErrWorkersNotSupported) {
+			t.Fatalf("expected ErrWorkersNotSupported got %v", result.Error)
+		}
+	}
+	if handled != 0 {
+		t.Fatalf("expected no handled events got %d", handled)
+	}
+}
+
+func TestBatchProjection(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sizes []int
+	p := eventsourcing.NewBatchProjection(es.All(0, 3), func(ctx context.Context, events []eventsourcing.Event) error {
+		sizes = append(sizes, len(events))
+		return nil
+	})
+	p.BatchSize = 2
+
+	result := p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	// Born 1 + AgedOneYear 4 = 5 events in batches of 2, 2 and 1
+	if len(sizes) != 3 || sizes[0] != 2 || sizes[1] != 2 || sizes[2] != 1 {
+		t.Fatalf("expected batches of 2, 2 and 1 was %v", sizes)
+	}
+	if result.LastHandledEvent.GlobalVersion() != 5 {
+		t.Fatalf("expected last handled event to be 5 was %d", result.LastHandledEvent.GlobalVersion())
+	}
+}
+
+func TestBatchError(t *testing.T) {
+	// setup
+	es := memory.Create()
+	cs := csmemory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetchFrom := func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	}
+
+	fail := true
+	var handled []eventsourcing.Version
+	p := eventsourcing.NewCheckpointBatchProjection("persons", cs, fetchFrom, func(ctx context.Context, events []eventsourcing.Event) error {
+		for i, event := range events {
+			if event.GlobalVersion() == 4 && fail {
+				return &eventsourcing.BatchError{Index: i, Err: errors.New("failed")}
+			}
+			handled = append(handled, event.GlobalVersion())
+		}
+		return nil
+	})
+	p.BatchSize = 3
+
+	result := p.RunToEnd(context.Background())
+	if result.Error == nil {
+		t.Fatal("expected error")
+	}
+	var batchErr *eventsourcing.BatchError
+	if !errors.As(result.Error, &batchErr) || batchErr.Index != 0 {
+		t.Fatalf("expected batch error on index 0 was %v", result.Error)
+	}
+	if result.LastHandledEvent.GlobalVersion() != 3 {
+		t.Fatalf("expected last handled event to be 3 was %d", result.LastHandledEvent.GlobalVersion())
+	}
+	checkpoint, err := cs.Load(context.Background(), "persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint != 3 {
+		t.Fatalf("expected checkpoint to be 3 was %d", checkpoint)
+	}
+
+	// continue from the failed event
+	fail = false
+	result = p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if len(handled) != 6 {
+		t.Fatalf("expected 6 handled events was %v", handled)
+	}
+	for i, v := range handled {
+		if v != eventsourcing.Version(i+1) {
+			t.Fatalf("expected events to be handled in order was %v", handled)
+		}
+	}
+	checkpoint, err = cs.Load(context.Background(), "persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint != 6 {
+		t.Fatalf("expected checkpoint to be 6 was %d", checkpoint)
+	}
+}
+
+func TestBatchPartialRetry(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attempts := 0
+	var handled []eventsourcing.Version
+	p := eventsourcing.NewBatchProjection(es.All(0, 10), func(ctx context.Context, events []eventsourcing.Event) error {
+		attempts++
+		for i, event := range events {
+			if event.GlobalVersion() == 3 && attempts == 1 {
+				return &eventsourcing.BatchError{Index: i, Err: errors.New("temporary error")}
+			}
+			handled = append(handled, event.GlobalVersion())
+		}
+		return nil
+	})
+	p.OnError = eventsourcing.ErrorPolicy{Retries: 1, Backoff: time.Millisecond}
+
+	result := p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if attempts != 2 {
+		t.Fatalf("expected 2 attempts was %d", attempts)
+	}
+	// the retry should only get the events that was not handled
+	if len(handled) != 4 {
+		t.Fatalf("expected each event to be handled once was %v", handled)
+	}
+}
+
+func TestBatchErrorIndexOutOfRange(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, index := range []int{-1, 3, 5} {
+		p := eventsourcing.NewBatchProjection(es.All(0, 10), func(ctx context.Context, events []eventsourcing.Event) error {
+			return &eventsourcing.BatchError{Index: index, Err: errors.New("failed")}
+		})
+
+		// an index outside the batch fails the whole batch
+		result := p.RunToEnd(context.Background())
+		if result.Error == nil {
+			t.Fatalf("expected error on index %d", index)
+		}
+		if result.LastHandledEvent.GlobalVersion() != 0 {
+			t.Fatalf("expected no handled event on index %d was %d", index, result.LastHandledEvent.GlobalVersion())
+		}
+	}
+}
+
+func TestBatchDeadLetter(t *testing.T) {
+	// setup
+	es := memory.Create()
+	cs := csmemory.Create()
+	ds := dlmemory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetchFrom := func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	}
+
+	var handled []eventsourcing.Version
+	p := eventsourcing.NewCheckpointBatchProjection("persons", cs, fetchFrom, func(ctx context.Context, events []eventsourcing.Event) error {
+		if events[len(events)-1].GlobalVersion() == 5 {
+			return errors.New("failed batch")
+		}
+		for i, event := range events {
+			if event.GlobalVersion() == 2 {
+				return &eventsourcing.BatchError{Index: i, Err: errors.New("poison event")}
+			}
+			handled = append(handled, event.GlobalVersion())
+		}
+		return nil
+	})
+	p.BatchSize = 3
+	p.OnError = eventsourcing.ErrorPolicy{Retries: 1, DeadLetter: ds}
+
+	// the poison event is skipped and the batch failing without a batch error is dead-lettered
+	result := p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if len(handled) != 2 || handled[0] != 1 || handled[1] != 3 {
+		t.Fatalf("expected events 1 and 3 to be handled was %v", handled)
+	}
+	if result.LastHandledEvent.GlobalVersion() != 5 {
+		t.Fatalf("expected last handled event to be 5 was %d", result.LastHandledEvent.GlobalVersion())
+	}
+	checkpoint, err := cs.Load(context.Background(), "persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint != 5 {
+		t.Fatalf("expected checkpoint to be 5 was %d", checkpoint)
+	}
+
+	deadLetters, err := p.DeadLetters(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deadLetters) != 3 {
+		t.Fatalf("expected three dead letters got %d", len(deadLetters))
+	}
+	for i, v := range []core.Version{2, 4, 5} {
+		if deadLetters[i].Event.GlobalVersion != v || deadLetters[i].Attempts != 2 {
+			t.Fatalf("unexpected dead letter %#v", deadLetters[i])
+		}
+	}
+}
+
+func TestTxDryRun(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := &transactor{Memory: csmemory.Create()}
+	p := eventsourcing.NewTxProjection("persons", tr, func(start core.Version) core.Fetcher {
+		return es.All(start, 100)
+	})
+	p.CheckpointEvery = 3
+	p.DryRun = true
+
+	result := p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if result.LastHandledEvent.GlobalVersion() != 10 {
+		t.Fatalf("expected last handled event to be 10 was %d", result.LastHandledEvent.GlobalVersion())
+	}
+	if len(tr.committed) != 0 {
+		t.Fatalf("expected no committed events got %d", len(tr.committed))
+	}
+	_, err = tr.Load(context.Background(), "persons")
+	if !errors.Is(err, core.ErrCheckpointNotFound) {
+		t.Fatalf("expected no checkpoint got %v", err)
+	}
+}
+
+func TestVersionedProjection(t *testing.T) {
+	// setup
+	es := memory.Create()
+	cs := csmemory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readModels := make(map[string]int)
+	build := func(target string) *eventsourcing.Projection {
+		return eventsourcing.NewCheckpointProjection(target, cs, func(start core.Version) core.Fetcher {
+			return es.All(start, 2)
+		}, func(event eventsourcing.Event) error {
+			readModels[target]++
+			return nil
+		})
+	}
+	var swapped []string
+	swap := func(ctx context.Context, target string) error {
+		swapped = append(swapped, target)
+		return nil
+	}
+
+	rebuild := func(version uint64, dryRun bool) {
+		t.Helper()
+		v := eventsourcing.NewVersionedProjection("persons", version, cs, build)
+		v.Swap = swap
+		v.Projection().DryRun = dryRun
+		err := v.Rebuild(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rebuild(1, false)
+	if readModels["persons_v1"] != 6 {
+		t.Fatalf("expected 6 events in persons_v1 was %d", readModels["persons_v1"])
+	}
+
+	// the active version is not rebuilt
+	rebuild(1, false)
+	if readModels["persons_v1"] != 6 {
+		t.Fatalf("expected 6 events in persons_v1 was %d", readModels["persons_v1"])
+	}
+
+	// a new version is built from the start into a new target
+	rebuild(2, false)
+	if readModels["persons_v2"] != 6 {
+		t.Fatalf("expected 6 events in persons_v2 was %d", readModels["persons_v2"])
+	}
+
+	// a dry run handles the events without swapping
+	rebuild(3, true)
+	if readModels["persons_v3"] != 6 {
+		t.Fatalf("expected 6 events in persons_v3 was %d", readModels["persons_v3"])
+	}
+	_, err = cs.Load(context.Background(), "persons_v3")
+	if !errors.Is(err, core.ErrCheckpointNotFound) {
+		t.Fatalf("expected no checkpoint for the dry run got %v", err)
+	}
+
+	if len(swapped) != 2 || swapped[0] != "persons_v1" || swapped[1] != "persons_v2" {
+		t.Fatalf("expected swap to persons_v1 and persons_v2 was %v", swapped)
+	}
+	v := eventsourcing.NewVersionedProjection("persons", 3, cs, build)
+	active, err := v.ActiveVersion(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active != 2 {
+		t.Fatalf("expected active version 2 was %d", active)
+	}
+	// the active version is not stored on a projection checkpoint
+	_, err = cs.Load(context.Background(), "persons")
+	if !errors.Is(err, core.ErrCheckpointNotFound) {
+		t.Fatalf("expected no checkpoint on the name got %v", err)
+	}
+}
+
+func TestGroupRestart(t *testing.T) {
+	// setup
+	es := memory.Create()
+	cs := csmemory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lock sync.Mutex
+	failures := 0
+	p := eventsourcing.NewCheckpointProjection("persons", cs, func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	}, func(event eventsourcing.Event) error {
+		lock.Lock()
+		defer lock.Unlock()
+		if event.GlobalVersion() == 2 && failures < 2 {
+			failures++
+			return errors.New("temporary error")
+		}
+		return nil
+	})
+	p.Restart = eventsourcing.RestartPolicy{Mode: eventsourcing.RestartBackoff, MaxAttempts: 3, Backoff: time.Millisecond}
+
+	g := eventsourcing.NewProjectionGroup(p)
+	g.Pace = time.Millisecond
+	g.Start()
+	defer g.Stop()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		status := g.Status()[0]
+		if status.LastHandledGlobalVersion == 3 {
+			if status.State != eventsourcing.ProjectionRunning {
+				t.Fatalf("expected state running was %s", status.State)
+			}
+			if status.Restarts != 2 {
+				t.Fatalf("expected 2 restarts was %d", status.Restarts)
+			}
+			if status.LastError == nil {
+				t.Fatal("expected last error to be set")
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("test timed out %+v", status)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestGroupRestartMaxAttempts(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ErrApplication = errors.New("application error")
+	p := eventsourcing.NewCheckpointProjection("persons", csmemory.Create(), func(start core.Version) core.Fetcher {
+		return es.All(start, 1)
+	}, func(event eventsourcing.Event) error {
+		return ErrApplication
+	})
+	p.Restart = eventsourcing.RestartPolicy{Mode: eventsourcing.RestartAlways, MaxAttempts: 2}
+
+	g := eventsourcing.NewProjectionGroup(p)
+	g.Start()
+	defer g.Stop()
+
+	select {
+	case err = <-g.ErrChan:
+	case <-time.After(time.Second):
+		t.Fatal("test timed out")
+	}
+	if !errors.Is(err, ErrApplication) {
+		t.Fatalf("expected application error got %v", err)
+	}
+	status := g.Status()[0]
+	if status.State != eventsourcing.ProjectionFailed {
+		t.Fatalf("expected state failed was %s", status.State)
+	}
+	if status.Restarts != 2 {
+		t.Fatalf("expected 2 restarts was %d", status.Restarts)
+	}
+}
+
+func TestGroupStopWithUnreadError(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := eventsourcing.NewProjection(es.All(0, 1), func(event eventsourcing.Event) error {
+		return errors.New("application error")
+	})
+	g := eventsourcing.NewProjectionGroup(p)
+	g.Start()
+	time.Sleep(time.Millisecond * 10)
+
+	// the error is not read from the error channel
+	stopped := make(chan struct{})
+	go func() {
+		g.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("stop blocked on the error channel")
+	}
+}
+
+func TestGroupAddRemove(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handled := make(chan eventsourcing.Version, 10)
+	p := eventsourcing.NewProjection(es.All(0, 1), func(event eventsourcing.Event) error {
+		handled <- event.GlobalVersion()
+		return nil
+	})
+	p.Name = "persons"
+
+	g := eventsourcing.NewProjectionGroup()
+	g.Pace = time.Millisecond
+	g.Start()
+	defer g.Stop()
+
+	g.Add(p, p)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-handled:
+		case <-time.After(time.Second):
+			t.Fatal("test timed out")
+		}
+	}
+	status := g.Status()
+	if len(status) != 1 || status[0].Name != "persons" || status[0].State != eventsourcing.ProjectionRunning {
+		t.Fatalf("expected the running projection in the status was %+v", status)
+	}
+
+	g.Remove(p)
+	if len(g.Status()) != 0 {
+		t.Fatalf("expected no projections in the group was %+v", g.Status())
+	}
+	err = createPersonEvent(es, "anka", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.TriggerSync()
+	select {
+	case v := <-handled:
+		t.Fatalf("removed projection handled event %d", v)
+	case <-time.After(time.Millisecond * 10):
+	}
+}
+
+func TestGroupShutdown(t *testing.T) {
+	// setup
+	es := memory.Create()
+	cs := csmemory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lock sync.Mutex
+	var handled int
+	p := eventsourcing.NewCheckpointBatchProjection("persons", cs, func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	}, func(ctx context.Context, events []eventsourcing.Event) error {
+		lock.Lock()
+		defer lock.Unlock()
+		handled += len(events)
+		return nil
+	})
+	// the batch is not handled by the running projection
+	p.BatchLinger = time.Hour
+
+	g := eventsourcing.NewProjectionGroup(p)
+	g.Start()
+	time.Sleep(time.Millisecond * 10)
+
+	err = g.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if handled != 3 {
+		t.Fatalf("expected the batch to be handled on shutdown, handled %d", handled)
+	}
+	checkpoint, err := cs.Load(context.Background(), "persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint != 3 {
+		t.Fatalf("expected checkpoint to be 3 was %d", checkpoint)
+	}
+	if state := g.Status()[0].State; state != eventsourcing.ProjectionStopped {
+		t.Fatalf("expected state stopped was %s", state)
+	}
+}
+
+func TestLag(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := eventsourcing.NewProjection(es.All(0, 2), func(event eventsourcing.Event) error {
+		return nil
+	})
+	_, err = p.Lag(context.Background())
+	if !errors.Is(err, eventsourcing.ErrNoHead) {
+		t.Fatalf("expected ErrNoHead got %v", err)
+	}
+	p.Head = es.Head
+
+	lag, err := p.Lag(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lag.Head != 6 || lag.Events != 6 {
+		t.Fatalf("expected head 6 and 6 events behind was %+v", lag)
+	}
+
+	p.RunOnce()
+	lag, err = p.Lag(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lag.LastHandledGlobalVersion != 2 || lag.Events != 4 {
+		t.Fatalf("expected last handled 2 and 4 events behind was %+v", lag)
+	}
+	if lag.Age <= 0 {
+		t.Fatalf("expected the age of the last handled event was %s", lag.Age)
+	}
+
+	p.RunToEnd(context.Background())
+	g := eventsourcing.NewProjectionGroup(p)
+	lags, err := g.Lag(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lags) != 1 || lags[0].Events != 0 || lags[0].Age != 0 {
+		t.Fatalf("expected no lag was %+v", lags)
+	}
+}
+
+func TestWaitFor(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	var lock sync.Mutex
+	projectedName := ""
+	p := eventsourcing.NewProjection(es.All(0, 1), func(event eventsourcing.Event) error {
+		switch e := event.Data().(type) {
+		case *Born:
+			lock.Lock()
+			projectedName = e.Name
+			lock.Unlock()
+		}
+		return nil
+	})
+
+	// the projection is not running
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	err := p.WaitFor(ctx, 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded got %v", err)
+	}
+
+	g := eventsourcing.NewProjectionGroup(p)
+	g.Pace = time.Hour
+	g.Start()
+	defer g.Stop()
+
+	// make sure the projection has finished it's first round
+	time.Sleep(time.Millisecond * 10)
+
+	err = createPersonEvent(es, "kalle", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := es.Head(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = g.WaitFor(ctx, eventsourcing.Version(head))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if projectedName != "kalle" {
+		t.Fatalf("expected projected name kalle was %q", projectedName)
+	}
+}
+
+func TestWaitForFiltered(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var born atomic.Int32
+	h := eventsourcing.NewHandlers()
+	eventsourcing.Handle(h, func(ctx context.Context, event eventsourcing.Event, data *Born) error {
+		born.Add(1)
+		return nil
+	})
+	p := h.Projection(es.All(0, 10))
+
+	g := eventsourcing.NewProjectionGroup(p)
+	g.Pace = time.Hour
+	g.Start()
+	defer g.Stop()
+
+	// the last event is skipped by the fetcher
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = p.WaitFor(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if born.Load() != 1 {
+		t.Fatalf("expected one handled event got %d", born.Load())
+	}
+}
+func TestLeaderProjection(t *testing.T) {
+	// setup
+	es := memory.Create()
+	cs := csmemory.Create()
+	leases := lsmemory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lock sync.Mutex
+	handled := make(map[string]int)
+	projection := func(owner string) *eventsourcing.Projection {
+		p := eventsourcing.NewCheckpointProjection("persons", cs, func(start core.Version) core.Fetcher {
+			return es.All(start, 10)
+		}, func(event eventsourcing.Event) error {
+			lock.Lock()
+			defer lock.Unlock()
+			handled[owner]++
+			return nil
+		})
+		p.Lease = &eventsourcing.Lease{Store: leases, Owner: owner, TTL: time.Millisecond * 100, Renew: time.Millisecond * 5}
+		return p
+	}
+	count := func() (int, int) {
+		lock.Lock()
+		defer lock.Unlock()
+		return handled["a"], handled["b"]
+	}
+
+	pa := projection("a")
+	ga := eventsourcing.NewProjectionGroup(pa)
+	ga.Pace = time.Millisecond
+	ga.Start()
+	time.Sleep(time.Millisecond * 20)
+
+	pb := projection("b")
+	gb := eventsourcing.NewProjectionGroup(pb)
+	gb.Pace = time.Millisecond
+	gb.Start()
+	defer gb.Stop()
+	time.Sleep(time.Millisecond * 20)
+
+	a, b := count()
+	if a != 3 || b != 0 {
+		t.Fatalf("expected only the leader to handle the events, a %d b %d", a, b)
+	}
+	if state := gb.Status()[0].State; state != eventsourcing.ProjectionStandby {
+		t.Fatalf("expected state standby was %s", state)
+	}
+
+	// the lease is released when the leader stops
+	ga.Stop()
+	err = createPersonEvent(es, "anka", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for b != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the new leader to handle the new events, a %d b %d", a, b)
+		}
+		time.Sleep(time.Millisecond)
+		a, b = count()
+	}
+	if a != 3 {
+		t.Fatalf("expected the stopped leader to not handle events, a %d", a)
+	}
+}
+
+func TestPartitionedProjection(t *testing.T) {
+	// setup
+	es := memory.Create()
+	cs := csmemory.Create()
+	coordinator := lsmemory.Create()
+	aggregate.Register(&Person{})
+
+	var lock sync.Mutex
+	handled := make(map[eventsourcing.Version]int)
+	count := func() (int, bool) {
+		lock.Lock()
+		defer lock.Unlock()
+		for _, n := range handled {
+			if n > 1 {
+				return len(handled), false
+			}
+		}
+		return len(handled), true
+	}
+	wait := func(events int) {
+		t.Helper()
+		for i := 0; i < 200; i++ {
+			n, once := count()
+			if !once {
+				t.Fatal("expected each event to be handled once")
+			}
+			if n == events {
+				return
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+		n, _ := count()
+		t.Fatalf("expected %d handled events got %d", events, n)
+	}
+	projection := func(member string) *eventsourcing.PartitionedProjection {
+		pp := eventsourcing.NewPartitionedProjection("persons", 4, cs, func(start core.Version) core.Fetcher {
+			return es.All(start, 10)
+		}, func(ctx context.Context, event eventsourcing.Event) error {
+			lock.Lock()
+			defer lock.Unlock()
+			handled[event.GlobalVersion()]++
+			return nil
+		})
+		pp.Coordinator = coordinator
+		pp.Member = member
+		pp.TTL = time.Millisecond * 200
+		pp.Heartbeat = time.Millisecond * 10
+		return pp
+	}
+
+	for i := 0; i < 10; i++ {
+		err := createPersonEvent(es, "kalle", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pa := projection("a")
+	pb := projection("b")
+	errs := make(chan error, 2)
+	go func() { errs <- pa.Run(ctx, time.Millisecond) }()
+	ctxB, cancelB := context.WithCancel(ctx)
+	go func() { errs <- pb.Run(ctxB, time.Millisecond) }()
+	wait(20)
+
+	// the partitions are spread over the members
+	for i := 0; i < 100 && (len(pa.Assigned()) != 2 || len(pb.Assigned()) != 2); i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+	if len(pa.Assigned()) != 2 || len(pb.Assigned()) != 2 {
+		t.Fatalf("expected two partitions each got %v and %v", pa.Assigned(), pb.Assigned())
+	}
+
+	// the partitions of the member leaving are moved to the live member
+	cancelB()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled got %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		err := createPersonEvent(es, "anka", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	wait(40)
+	if len(pa.Assigned()) != 4 {
+		t.Fatalf("expected all partitions assigned got %v", pa.Assigned())
+	}
+
+	// each partition stores its own checkpoint
+	var checkpoints int
+	for i := 0; i < 4; i++ {
+		_, err := cs.Load(context.Background(), pa.PartitionName(i))
+		if err == nil {
+			checkpoints++
+		}
+	}
+	if checkpoints < 2 {
+		t.Fatalf("expected the partitions to store checkpoints got %d", checkpoints)
+	}
+}
+
+func TestSharedReader(t *testing.T) {
+	// setup
+	es := memory.Create()
+	cs := csmemory.Create()
+	aggregate.Register(&Person{})
+
+	for i := 0; i < 5; i++ {
+		err := createPersonEvent(es, "kalle", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the laggard has handled the first event
+	err := cs.Save("laggard", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lock sync.Mutex
+	fetches := make(map[string]int)
+	fetchFrom := func(name string) core.FetcherFrom {
+		return func(start core.Version) core.Fetcher {
+			fetchF := es.All(start, 3)
+			return func() (core.Iterator, error) {
+				lock.Lock()
+				fetches[name]++
+				lock.Unlock()
+				return fetchF()
+			}
+		}
+	}
+	handled := make(map[string][]eventsourcing.Version)
+	projection := func(name string) *eventsourcing.Projection {
+		return eventsourcing.NewCheckpointProjection(name, cs, fetchFrom(name), func(event eventsourcing.Event) error {
+			lock.Lock()
+			defer lock.Unlock()
+			handled[name] = append(handled[name], event.GlobalVersion())
+			return nil
+		})
+	}
+	count := func(name string) int {
+		lock.Lock()
+		defer lock.Unlock()
+		return fetches[name]
+	}
+
+	g := eventsourcing.NewProjectionGroup(projection("a"), projection("b"), projection("laggard"))
+	g.Pace = time.Millisecond * 10
+	g.Reader = fetchFrom("reader")
+	g.Start()
+	defer g.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = g.WaitFor(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the projections that have caught up do not fetch from the event store
+	a, b, reader := count("a"), count("b"), count("reader")
+	for i := 0; i < 5; i++ {
+		err := createPersonEvent(es, "anka", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = g.WaitFor(ctx, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 50)
+	if count("a") != a || count("b") != b {
+		t.Fatalf("expected no fetches after catching up got a %d->%d b %d->%d", a, count("a"), b, count("b"))
+	}
+	if count("reader") <= reader {
+		t.Fatal("expected the shared reader to fetch the events")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	for name, from := range map[string]int{"a": 1, "b": 1, "laggard": 2} {
+		if len(handled[name]) != 21-from {
+			t.Fatalf("expected %s to handle %d events got %v", name, 21-from, handled[name])
+		}
+		for i, version := range handled[name] {
+			if version != eventsourcing.Version(from+i) {
+				t.Fatalf("expected %s to handle the events in order got %v", name, handled[name])
+			}
+		}
+	}
+}
+
+func TestSharedReaderFilteredFetcher(t *testing.T) {
+	// setup
+	es := memory.Create()
+	cs := csmemory.Create()
+	aggregate.Register(&Person{})
+
+	for i := 0; i < 4; i++ {
+		err := createPersonEvent(es, "kalle", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var lock sync.Mutex
+	var born, all []eventsourcing.Version
+	filtered := eventsourcing.NewCheckpointProjection("born", cs, func(start core.Version) core.Fetcher {
+		return fetcher.Reason(es.All(start, 10), "Born")
+	}, func(event eventsourcing.Event) error {
+		lock.Lock()
+		defer lock.Unlock()
+		born = append(born, event.GlobalVersion())
+		return nil
+	})
+	unfiltered := eventsourcing.NewCheckpointProjection("all", cs, func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	}, func(event eventsourcing.Event) error {
+		lock.Lock()
+		defer lock.Unlock()
+		all = append(all, event.GlobalVersion())
+		return nil
+	})
+
+	g := eventsourcing.NewProjectionGroup(filtered, unfiltered)
+	g.Pace = time.Millisecond * 10
+	g.Reader = func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	}
+	g.Start()
+	defer g.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := g.WaitFor(ctx, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// events created after the projections have caught up with the reader
+	for i := 0; i < 2; i++ {
+		err := createPersonEvent(es, "anka", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = g.WaitFor(ctx, 18)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(all) != 18 {
+		t.Fatalf("expected the unfiltered projection to handle 18 events got %v", all)
+	}
+	expected := []eventsourcing.Version{1, 4, 7, 10, 13, 16}
+	if len(born) != len(expected) {
+		t.Fatalf("expected the filtered projection to handle the Born events %v got %v", expected, born)
+	}
+	for i, version := range expected {
+		if born[i] != version {
+			t.Fatalf("expected the filtered projection to handle the Born events %v got %v", expected, born)
+		}
+	}
+}
+
+func TestSharedReaderFullBuffer(t *testing.T) {
+	// setup
+	es := memory.Create()
+	cs := csmemory.Create()
+	aggregate.Register(&Person{})
+
+	var lock sync.Mutex
+	var handled []eventsourcing.Version
+	release := make(chan struct{})
+	p := eventsourcing.NewCheckpointProjection("persons", cs, func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	}, func(event eventsourcing.Event) error {
+		// block on the first event while the buffer fills up
+		if event.GlobalVersion() == 1 {
+			<-release
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		handled = append(handled, event.GlobalVersion())
+		return nil
+	})
+
+	g := eventsourcing.NewProjectionGroup(p)
+	g.Pace = time.Millisecond * 5
+	g.Reader = func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	}
+	g.Buffer = 2
+	g.Start()
+	defer g.Stop()
+
+	// let the projection attach to the reader before there are any events
+	time.Sleep(time.Millisecond * 20)
+	for i := 0; i < 5; i++ {
+		err := createPersonEvent(es, "kalle", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(time.Millisecond * 50)
+	close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := g.WaitFor(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	for i, version := range handled {
+		if version != eventsourcing.Version(i+1) {
+			t.Fatalf("expected the events to be handled once in order got %v", handled)
+		}
+	}
+	if len(handled) != 10 {
+		t.Fatalf("expected 10 handled events got %v", handled)
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an event that is not registered is skipped before it's deserialized even on a strict projection
+	err = es.Save([]core.Event{{AggregateID: "1", Version: 1, AggregateType: "Grave", Reason: "Died", Timestamp: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var name string
+	var aged int
+	h := eventsourcing.NewHandlers()
+	eventsourcing.Handle(h, func(ctx context.Context, event eventsourcing.Event, born *Born) error {
+		name = born.Name
+		return nil
+	})
+	// the handler can take the event data as a value
+	eventsourcing.Handle(h, func(ctx context.Context, event eventsourcing.Event, data AgedOneYear) error {
+		aged++
+		return nil
+	})
+
+	p := h.Projection(es.All(0, 10))
+	result := p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if name != "kalle" {
+		t.Fatalf("expected name kalle got %q", name)
+	}
+	if aged != 2 {
+		t.Fatalf("expected 2 AgedOneYear events got %d", aged)
+	}
+	if result.LastHandledEvent.GlobalVersion() != 3 {
+		t.Fatalf("expected last handled event 3 got %d", result.LastHandledEvent.GlobalVersion())
+	}
+}
+
+func TestHandlersError(t *testing.T) {
+	// setup
+	es := memory.Create()
+	cs := csmemory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handlerErr := errors.New("handler error")
+	h := eventsourcing.NewHandlers()
+	eventsourcing.Handle(h, func(ctx context.Context, event eventsourcing.Event, aged *AgedOneYear) error {
+		return handlerErr
+	})
+	p := h.CheckpointProjection("persons", cs, func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	})
+	result := p.RunToEnd(context.Background())
+	if !errors.Is(result.Error, handlerErr) {
+		t.Fatalf("expected handler error got %v", result.Error)
+	}
+	// the Born event has no handler and is skipped in the fetcher
+	version, err := cs.Load(context.Background(), "persons")
+	if err != nil && !errors.Is(err, core.ErrCheckpointNotFound) {
+		t.Fatal(err)
+	}
+	if version != 0 {
+		t.Fatalf("expected no handled events before the failing event got checkpoint %d", version)
+	}
+}
+
+func TestPauseSeekReset(t *testing.T) {
+	// setup
+	es := memory.Create()
+	cs := csmemory.Create()
+	aggregate.Register(&Person{})
+
+	for i := 0; i < 3; i++ {
+		err := createPersonEvent(es, "kalle", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond * 2)
+	}
+
+	var lock sync.Mutex
+	var handled []eventsourcing.Event
+	p := eventsourcing.NewCheckpointProjection("persons", cs, func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	}, func(event eventsourcing.Event) error {
+		lock.Lock()
+		defer lock.Unlock()
+		handled = append(handled, event)
+		return nil
+	})
+	// take returns the global versions of the handled events since the last call
+	take := func() []eventsourcing.Version {
+		lock.Lock()
+		defer lock.Unlock()
+		var versions []eventsourcing.Version
+		for _, event := range handled {
+			versions = append(versions, event.GlobalVersion())
+		}
+		handled = nil
+		return versions
+	}
+	expect := func(versions []eventsourcing.Version, from, to eventsourcing.Version) {
+		t.Helper()
+		if len(versions) != int(to-from+1) || versions[0] != from || versions[len(versions)-1] != to {
+			t.Fatalf("expected events %d to %d got %v", from, to, versions)
+		}
+	}
+
+	g := eventsourcing.NewProjectionGroup(p)
+	g.Pace = time.Millisecond * 5
+	g.Start()
+	defer g.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := p.WaitFor(ctx, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock.Lock()
+	born := handled[4]
+	lock.Unlock()
+	expect(take(), 1, 6)
+
+	// a paused projection does not handle new events
+	p.Pause()
+	err = createPersonEvent(es, "anka", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.TriggerSync()
+	time.Sleep(time.Millisecond * 20)
+	if versions := take(); len(versions) != 0 {
+		t.Fatalf("expected no handled events while paused got %v", versions)
+	}
+	if status := g.Status(); status[0].State != eventsourcing.ProjectionPaused {
+		t.Fatalf("expected paused state got %s", status[0].State)
+	}
+	p.Resume()
+	err = p.WaitFor(ctx, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(take(), 7, 8)
+
+	// seek replays from the global version and stores the checkpoint
+	err = p.Seek(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.WaitFor(ctx, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(take(), 3, 8)
+
+	err = p.Reset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.WaitFor(ctx, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(take(), 1, 8)
+
+	// seek to the born event of the third person
+	err = p.SeekTime(ctx, born.Timestamp())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.WaitFor(ctx, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(take(), 5, 8)
+
+	version, err := cs.Load(context.Background(), "persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 8 {
+		t.Fatalf("expected checkpoint 8 got %d", version)
+	}
+
+	// a projection without a fetcher from can't seek
+	err = eventsourcing.NewProjection(es.All(0, 10), func(event eventsourcing.Event) error { return nil }).Seek(1)
+	if !errors.Is(err, eventsourcing.ErrNotSeekable) {
+		t.Fatalf("expected ErrNotSeekable got %v", err)
+	}
+}
+
+// personCount is the state of a state projection
+type personCount struct {
+	Born int
+	Aged int
+}
+
+func TestStateProjection(t *testing.T) {
+	// setup
+	es := memory.Create()
+	ss := ssmemory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var applied int
+	projection := func() *eventsourcing.StateProjection[personCount] {
+		return eventsourcing.NewStateProjection("persons", ss, func(start core.Version) core.Fetcher {
+			return es.All(start, 10)
+		}, func(ctx context.Context, event eventsourcing.Event, state *personCount) error {
+			applied++
+			switch event.Data().(type) {
+			case *Born:
+				state.Born++
+			case *AgedOneYear:
+				state.Aged++
+			}
+			return nil
+		})
+	}
+	expect := func(s *eventsourcing.StateProjection[personCount], born, aged int) {
+		t.Helper()
+		s.Read(func(state *personCount) {
+			if state.Born != born || state.Aged != aged {
+				t.Fatalf("expected %d born and %d aged got %+v", born, aged, *state)
+			}
+		})
+	}
+
+	s := projection()
+	result := s.Projection().RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	expect(s, 1, 2)
+
+	// the state is restored and only the new events are applied
+	err = createPersonEvent(es, "anka", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied = 0
+	s = projection()
+	result = s.Projection().RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	expect(s, 2, 3)
+	if applied != 2 {
+		t.Fatalf("expected 2 applied events after restore got %d", applied)
+	}
+	snapshot, err := ss.Get(context.Background(), "persons", "projection")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.GlobalVersion != 5 {
+		t.Fatalf("expected the state stored on global version 5 got %d", snapshot.GlobalVersion)
+	}
+
+	// the state can't be moved back to an earlier version
+	err = s.Projection().Seek(2)
+	if !errors.Is(err, eventsourcing.ErrNotSeekable) {
+		t.Fatalf("expected ErrNotSeekable got %v", err)
+	}
+
+	// reset clears the state and rebuilds it from the start
+	err = s.Projection().Reset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(s, 0, 0)
+	result = s.Projection().RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	expect(s, 2, 3)
+}
+
+func TestStateProjectionRetry(t *testing.T) {
+	// setup
+	es := memory.Create()
+	ss := ssmemory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fail := true
+	s := eventsourcing.NewStateProjection("persons", ss, func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	}, func(ctx context.Context, event eventsourcing.Event, state *personCount) error {
+		state.Aged++
+		if event.GlobalVersion() == 2 && fail {
+			fail = false
+			return errors.New("temporary error")
+		}
+		return nil
+	})
+	s.Projection().OnError = eventsourcing.ErrorPolicy{Retries: 1}
+
+	// the failed event is not partly applied when it's retried
+	result := s.Projection().RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	s.Read(func(state *personCount) {
+		if state.Aged != 3 {
+			t.Fatalf("expected each event to be applied once got %d", state.Aged)
+		}
+	})
+}
+
+func TestStateProjectionWorkers(t *testing.T) {
+	// setup
+	es := memory.Create()
+	ss := ssmemory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := eventsourcing.NewStateProjection("persons", ss, func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	}, func(ctx context.Context, event eventsourcing.Event, state *personCount) error {
+		state.Aged++
+		return nil
+	})
+	s.Projection().Workers = 2
+
+	// the state is stored with the checkpoint and has to be applied in order
+	result := s.Projection().RunToEnd(context.Background())
+	if !errors.Is(result.Error, eventsourcing.ErrWorkersNotSupported) {
+		t.Fatalf("expected ErrWorkersNotSupported got %v", result.Error)
+	}
+	s.Read(func(state *personCount) {
+		if state.Aged != 0 {
+			t.Fatalf("expected no applied events got %d", state.Aged)
+		}
+	})
+}
+
+func TestDependsOn(t *testing.T) {
+	// setup
+	es := memory.Create()
+	cs := csmemory.Create()
+	aggregate.Register(&Person{})
+
+	// 6 events
+	for i := 0; i < 3; i++ {
+		err := createPersonEvent(es, "kalle", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	fetchFrom := func(start core.Version) core.Fetcher {
+		return es.All(start, 1)
+	}
+
+	var lock sync.Mutex
+	var upstreamHandled eventsourcing.Version
+	var violations []eventsourcing.Version
+	release := make(chan struct{})
+	customers := eventsourcing.NewCheckpointProjection("customers", cs, fetchFrom, func(event eventsourcing.Event) error {
+		<-release
+		lock.Lock()
+		defer lock.Unlock()
+		upstreamHandled = event.GlobalVersion()
+		return nil
+	})
+	orders := eventsourcing.NewCheckpointProjection("orders", cs, func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	}, func(event eventsourcing.Event) error {
+		lock.Lock()
+		defer lock.Unlock()
+		if event.GlobalVersion() > upstreamHandled {
+			violations = append(violations, event.GlobalVersion())
+		}
+		return nil
+	})
+
+	g := eventsourcing.NewProjectionGroup(customers, orders)
+	// the downstream projection is triggered when the upstream projection advances
+	g.Pace = time.Minute
+	err := g.DependsOn("orders", "customers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = g.DependsOn("customers", "orders")
+	if !errors.Is(err, eventsourcing.ErrDependencyCycle) {
+		t.Fatalf("expected dependency cycle got %v", err)
+	}
+	g.Start()
+	defer g.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	for i := 0; i < 3; i++ {
+		release <- struct{}{}
+	}
+	err = orders.WaitFor(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the downstream projection does not pass the upstream projection
+	time.Sleep(time.Millisecond * 50)
+	for _, status := range g.Status() {
+		if status.Name == "orders" && status.LastHandledGlobalVersion != 3 {
+			t.Fatalf("expected orders to be held at 3 got %d", status.LastHandledGlobalVersion)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		release <- struct{}{}
+	}
+	err = g.WaitFor(ctx, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if len(violations) > 0 {
+		t.Fatalf("orders handled events before customers %v", violations)
+	}
+}
+
+func TestDependsOnFiltered(t *testing.T) {
+	// setup
+	es := memory.Create()
+	cs := csmemory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetchFrom := func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	}
+
+	// the upstream projection skips the AgedOneYear events in its fetcher
+	h := eventsourcing.NewHandlers()
+	eventsourcing.Handle(h, func(ctx context.Context, event eventsourcing.Event, data *Born) error {
+		return nil
+	})
+	customer := h.CheckpointProjection("customer", cs, fetchFrom)
+	var handled atomic.Int32
+	summary := eventsourcing.NewCheckpointProjection("summary", cs, fetchFrom, func(event eventsourcing.Event) error {
+		handled.Add(1)
+		return nil
+	})
+
+	g := eventsourcing.NewProjectionGroup(customer, summary)
+	g.Pace = time.Minute
+	err = g.DependsOn("summary", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Start()
+	defer g.Stop()
+
+	// the downstream projection is not held back by the events the upstream projection skips
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = summary.WaitFor(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handled.Load() != 3 {
+		t.Fatalf("expected 3 handled events got %d", handled.Load())
+	}
+}
+
+// Statistics aggregate holding events derived from persons
+type Statistics struct {
+	aggregate.Root
+}
+
+// YearPassed derived event
+type YearPassed struct {
+	PersonID string
+}
+
+func (s *Statistics) Transition(event eventsourcing.Event) {}
+
+func (s *Statistics) Register(f aggregate.RegisterFunc) {
+	f(&YearPassed{})
+}
+
+func TestDerivedProjection(t *testing.T) {
+	// setup
+	es := memory.Create()
+	cs := csmemory.Create()
+	aggregate.Register(&Person{})
+	aggregate.Register(&Statistics{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	derive := func(ctx context.Context, event eventsourcing.Event, emit eventsourcing.EmitFunc) error {
+		switch event.Data().(type) {
+		case *AgedOneYear:
+			return emit("Statistics", "years", &YearPassed{PersonID: event.AggregateID()})
+		}
+		return nil
+	}
+	fetchFrom := func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	}
+	run := func(p *eventsourcing.Projection) {
+		t.Helper()
+		result := p.RunToEnd(context.Background())
+		if result.Error != nil {
+			t.Fatal(result.Error)
+		}
+	}
+	// sources returns the source global versions of the derived events
+	sources := func() []uint64 {
+		t.Helper()
+		iterator, err := es.Get(context.Background(), "years", "Statistics", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer iterator.Close()
+		var sources []uint64
+		for iterator.Next() {
+			event, err := iterator.Value()
+			if err != nil {
+				t.Fatal(err)
+			}
+			metadata := map[string]interface{}{}
+			err = json.Unmarshal(event.Metadata, &metadata)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sources = append(sources, uint64(metadata[eventsourcing.SourceGlobalVersion].(float64)))
+		}
+		return sources
+	}
+	expect := func(expected ...uint64) {
+		t.Helper()
+		got := sources()
+		if len(got) != len(expected) {
+			t.Fatalf("expected derived events from %v got %v", expected, got)
+		}
+		for i := range got {
+			if got[i] != expected[i] {
+				t.Fatalf("expected derived events from %v got %v", expected, got)
+			}
+		}
+	}
+
+	p := eventsourcing.NewDerivedProjection("years", cs, fetchFrom, es, derive)
+	run(p)
+	expect(2, 3)
+
+	// events handled again are not emitted again
+	err = p.Reset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	run(p)
+	expect(2, 3)
+
+	// a new projection reads the position of the derived stream from the event store
+	err = cs.Save("years", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p = eventsourcing.NewDerivedProjection("years", cs, fetchFrom, es, derive)
+	run(p)
+	expect(2, 3)
+
+	// new events are derived, the derived events are skipped by the derive function
+	err = createPersonEvent(es, "anka", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run(p)
+	expect(2, 3, 7)
+}
+
+func TestWindowProjection(t *testing.T) {
+	// setup
+	es := memory.Create()
+	ss := ssmemory.Create()
+	aggregate.Register(&Person{})
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var id int
+	// born saves a person born at the minutes after the start
+	born := func(minutes ...int) {
+		t.Helper()
+		for _, m := range minutes {
+			id++
+			err := es.Save([]core.Event{{
+				AggregateID:   fmt.Sprintf("person-%d", id),
+				AggregateType: "Person",
+				Reason:        "Born",
+				Version:       1,
+				Timestamp:     start.Add(time.Duration(m) * time.Minute),
+				Data:          []byte(`{"Name":"kalle"}`),
+			}})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	fetchFrom := func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	}
+
+	var closed []eventsourcing.Window[int]
+	count := func() *eventsourcing.WindowProjection[int] {
+		w := eventsourcing.NewWindowProjection("births", ss, fetchFrom, time.Hour, func(ctx context.Context, event eventsourcing.Event, state *int) error {
+			*state++
+			return nil
+		}, func(ctx context.Context, window eventsourcing.Window[int]) error {
+			closed = append(closed, window)
+			return nil
+		})
+		w.Lateness = time.Minute * 10
+		return w
+	}
+	run := func(w *eventsourcing.WindowProjection[int]) {
+		t.Helper()
+		result := w.Projection().RunToEnd(context.Background())
+		if result.Error != nil {
+			t.Fatal(result.Error)
+		}
+	}
+	expect := func(windows []eventsourcing.Window[int], expected ...eventsourcing.Window[int]) {
+		t.Helper()
+		if len(windows) != len(expected) {
+			t.Fatalf("expected windows %v got %v", expected, windows)
+		}
+		for i := range windows {
+			if !windows[i].Start.Equal(expected[i].Start) || !windows[i].End.Equal(expected[i].End) || windows[i].State != expected[i].State {
+				t.Fatalf("expected windows %v got %v", expected, windows)
+			}
+		}
+	}
+	window := func(from, to, count int) eventsourcing.Window[int] {
+		return eventsourcing.Window[int]{Start: start.Add(time.Duration(from) * time.Minute), End: start.Add(time.Duration(to) * time.Minute), State: count}
+	}
+
+	// the late event at 55 is added before the window is closed at 70, the late event at 30 is dropped
+	born(10, 50, 65, 55, 80, 30, 150)
+	w := count()
+	run(w)
+	expect(closed, window(0, 60, 3), window(60, 120, 2))
+	expect(w.Open(), window(120, 180, 1))
+
+	// the open windows are restored
+	w = count()
+	run(w)
+	expect(w.Open(), window(120, 180, 1))
+	born(195)
+	run(w)
+	expect(closed, window(0, 60, 3), window(60, 120, 2), window(120, 180, 1))
+	expect(w.Open(), window(180, 240, 1))
+
+	// sliding windows, each event belongs to two windows
+	es = memory.Create()
+	closed = nil
+	w = eventsourcing.NewWindowProjection("sliding", ss, fetchFrom, time.Hour*2, func(ctx context.Context, event eventsourcing.Event, state *int) error {
+		*state++
+		return nil
+	}, func(ctx context.Context, window eventsourcing.Window[int]) error {
+		closed = append(closed, window)
+		return nil
+	})
+	w.Slide = time.Hour
+	born(30, 90, 210)
+	run(w)
+	expect(closed, window(-60, 60, 1), window(0, 120, 2), window(60, 180, 1))
+	expect(w.Open(), window(120, 240, 1), window(180, 300, 1))
+}
+
+func TestWindowProjectionRetry(t *testing.T) {
+	// setup
+	es := memory.Create()
+	ss := ssmemory.Create()
+	aggregate.Register(&Person{})
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, m := range []int{10, 70} {
+		err := es.Save([]core.Event{{
+			AggregateID:   fmt.Sprintf("person-%d", i),
+			AggregateType: "Person",
+			Reason:        "Born",
+			Version:       1,
+			Timestamp:     start.Add(time.Duration(m) * time.Minute),
+			Data:          []byte(`{"Name":"kalle"}`),
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fail := true
+	var closed []eventsourcing.Window[int]
+	w := eventsourcing.NewWindowProjection("births", ss, func(start core.Version) core.Fetcher {
+		return es.All(start, 10)
+	}, time.Hour, func(ctx context.Context, event eventsourcing.Event, state *int) error {
+		*state++
+		return nil
+	}, func(ctx context.Context, window eventsourcing.Window[int]) error {
+		if fail {
+			fail = false
+			return errors.New("temporary error")
+		}
+		closed = append(closed, window)
+		return nil
+	})
+	w.Projection().OnError = eventsourcing.ErrorPolicy{Retries: 1}
+
+	// the event closing the window is added once when the closed handler is retried
+	result := w.Projection().RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if len(closed) != 1 || closed[0].State != 1 {
+		t.Fatalf("expected one closed window with one event got %v", closed)
+	}
+	open := w.Open()
+	if len(open) != 1 || open[0].State != 1 {
+		t.Fatalf("expected one open window with one event got %v", open)
+	}
+}
@@ -0,0 +1,30 @@
+package sql
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/r23vme/eventsourcing"
+	cssql "github.com/r23vme/eventsourcing/checkpointstore/sql"
+)
+
+var sqlite = dialect{
+	quote: quoteDouble,
+	placeholder: func(i int) string {
+		return fmt.Sprintf("?%d", i)
+	},
+	upsert: upsertOnConflict,
+}
+
+// NewSQLite creates the table in the SQLite database if it does not exist. The checkpoint is stored in the
+// checkpoints table of the SQL checkpoint store.
+func NewSQLite(db *sql.DB, name string, columns ...Column) (*Table, error) {
+	cs, err := cssql.NewSQLite(db)
+	if err != nil {
+		return nil, err
+	}
+	statement := "CREATE TABLE IF NOT EXISTS " + definition(sqlite, name, "VARCHAR", columns)
+	return newTable(db, name, sqlite, statement, func(f func(tx *sql.Tx, event eventsourcing.Event) error) eventsourcing.Transactor {
+		return cs.Transactor(f)
+	})
+}
@@ -0,0 +1,24 @@
+package sql_test
+
+import (
+	gosql "database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	cssql "github.com/r23vme/eventsourcing/checkpointstore/sql"
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/readmodel/sql"
+)
+
+func TestSQLite(t *testing.T) {
+	db, err := gosql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "test.db")+"?_journal_mode=WAL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	testTable(t, db, sql.NewSQLite, func(db *gosql.DB) (core.CheckpointStore, error) {
+		return cssql.NewSQLite(db)
+	})
+}
@@ -0,0 +1,54 @@
+package sql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/r23vme/eventsourcing"
+	cssql "github.com/r23vme/eventsourcing/checkpointstore/sql"
+)
+
+var sqlserver = dialect{
+	quote: func(name string) string {
+		return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+	},
+	placeholder: func(i int) string {
+		return fmt.Sprintf("@p%d", i)
+	},
+	upsert: upsertMerge,
+}
+
+// NewSQLServer creates the table in the SQL Server database if it does not exist. The checkpoint is stored in the
+// checkpoints table of the SQL checkpoint store.
+func NewSQLServer(db *sql.DB, name string, columns ...Column) (*Table, error) {
+	cs, err := cssql.NewSQLServer(db)
+	if err != nil {
+		return nil, err
+	}
+	statement := fmt.Sprintf("IF OBJECT_ID(N'%s', 'U') IS NULL BEGIN CREATE TABLE %s END",
+		strings.ReplaceAll(sqlserver.quote(name), "'", "''"), definition(sqlserver, name, "NVARCHAR(255)", columns))
+	return newTable(db, name, sqlserver, statement, func(f func(tx *sql.Tx, event eventsourcing.Event) error) eventsourcing.Transactor {
+		return cs.Transactor(f)
+	})
+}
+
+// upsertMerge is the upsert statement in SQL Server
+func upsertMerge(t *Table, columns []string, placeholders []string) string {
+	id := t.dialect.quote("id")
+	source := []string{t.dialect.placeholder(1) + " AS " + id}
+	values := []string{"source." + id}
+	set := make([]string, len(columns))
+	for i, column := range columns {
+		source = append(source, placeholders[i]+" AS "+column)
+		values = append(values, "source."+column)
+		set[i] = column + " = source." + column
+	}
+	statement := fmt.Sprintf("MERGE %s WITH (HOLDLOCK) AS target USING (SELECT %s) AS source ON target.%s = source.%s ",
+		t.dialect.quote(t.name), strings.Join(source, ", "), id, id)
+	if len(set) > 0 {
+		statement += "WHEN MATCHED THEN UPDATE SET " + strings.Join(set, ", ") + " "
+	}
+	return statement + fmt.Sprintf("WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s);",
+		strings.Join(append([]string{id}, columns...), ", "), strings.Join(values, ", "))
+}
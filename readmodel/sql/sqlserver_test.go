@@ -0,0 +1,59 @@
+package sql_test
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	_ "github.com/denisenkom/go-mssqldb"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	cssql "github.com/r23vme/eventsourcing/checkpointstore/sql"
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/readmodel/sql"
+)
+
+func TestSQLServer(t *testing.T) {
+	ctx := context.Background()
+
+	req := testcontainers.ContainerRequest{
+		Image:        "mcr.microsoft.com/mssql/server:2019-latest",
+		ExposedPorts: []string{"1433/tcp"},
+		Env: map[string]string{
+			"ACCEPT_EULA": "Y",
+			"SA_PASSWORD": "YourStrong(!)Password",
+		},
+		WaitingFor: wait.ForLog("SQL Server is now ready for client connections").WithStartupTimeout(2 * time.Minute),
+	}
+	mssqlC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		t.Fatalf("failed to start container: %v", err)
+	}
+	defer mssqlC.Terminate(ctx)
+
+	host, _ := mssqlC.Host(ctx)
+	port, _ := mssqlC.MappedPort(ctx, "1433")
+
+	dsn := fmt.Sprintf("sqlserver://sa:YourStrong(!)Password@%s:%s?database=master", host, port.Port())
+	var db *gosql.DB
+	for i := 0; i < 10; i++ {
+		db, err = gosql.Open("sqlserver", dsn)
+		if err == nil && db.Ping() == nil {
+			break
+		}
+		time.Sleep(2 * time.Second)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	testTable(t, db, sql.NewSQLServer, func(db *gosql.DB) (core.CheckpointStore, error) {
+		return cssql.NewSQLServer(db)
+	})
+}
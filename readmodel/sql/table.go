@@ -0,0 +1,207 @@
+package sql
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/r23vme/eventsourcing"
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/fetcher"
+	"github.com/r23vme/eventsourcing/internal"
+)
+
+// Column is a column in the table
+type Column struct {
+	Name string
+	// Type is the column type in the database, ex. INTEGER
+	Type string
+}
+
+// Values are the column values of a row
+type Values map[string]interface{}
+
+// Expr is a SQL expression used as a column value, ex. Expr("deposits + 1") in an update. It's put into the
+// statement as is and must not contain input from the events. Only expressions in updates can refer to the
+// columns of the row.
+type Expr string
+
+// operation changes the row of the aggregate in the transaction
+type operation func(tx *sql.Tx, event eventsourcing.Event) error
+
+// Table is a read model table where each row is keyed on the aggregate id in the id column. The rows are changed
+// by the operations registered on the event types and the checkpoint is stored on the table name in the same
+// transaction as the rows.
+type Table struct {
+	name       string
+	dialect    dialect
+	transactor func(f func(tx *sql.Tx, event eventsourcing.Event) error) eventsourcing.Transactor
+	operations map[reflect.Type]operation
+	reasons    []string
+}
+
+// dialect builds the statements for a database
+type dialect struct {
+	quote       func(name string) string
+	placeholder func(i int) string
+	upsert      func(t *Table, columns []string, values []string) string
+}
+
+// Name returns the name of the table
+func (t *Table) Name() string {
+	return t.name
+}
+
+// Insert registers an insert of a row with the values for the event type T
+func Insert[T any](t *Table, values func(event eventsourcing.Event, data T) Values) *Table {
+	register(t, values, func(tx *sql.Tx, id string, v Values) error {
+		columns, placeholders, args := t.columns(v, 2)
+		statement := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+			t.dialect.quote(t.name), strings.Join(append([]string{t.dialect.quote("id")}, columns...), ", "), strings.Join(append([]string{t.dialect.placeholder(1)}, placeholders...), ", "))
+		_, err := tx.Exec(statement, append([]interface{}{id}, args...)...)
+		return err
+	})
+	return t
+}
+
+// Upsert registers an insert of a row with the values, or update of the columns in the values if the row
+// exists, for the event type T
+func Upsert[T any](t *Table, values func(event eventsourcing.Event, data T) Values) *Table {
+	register(t, values, func(tx *sql.Tx, id string, v Values) error {
+		columns, placeholders, args := t.columns(v, 2)
+		statement := t.dialect.upsert(t, columns, placeholders)
+		_, err := tx.Exec(statement, append([]interface{}{id}, args...)...)
+		return err
+	})
+	return t
+}
+
+// Update registers an update of the columns in the values on the row for the event type T
+func Update[T any](t *Table, values func(event eventsourcing.Event, data T) Values) *Table {
+	register(t, values, func(tx *sql.Tx, id string, v Values) error {
+		columns, placeholders, args := t.columns(v, 2)
+		if len(columns) == 0 {
+			return nil
+		}
+		set := make([]string, len(columns))
+		for i := range columns {
+			set[i] = columns[i] + " = " + placeholders[i]
+		}
+		statement := fmt.Sprintf("UPDATE %s SET %s WHERE %s = %s", t.dialect.quote(t.name), strings.Join(set, ", "), t.dialect.quote("id"), t.dialect.placeholder(1))
+		_, err := tx.Exec(statement, append([]interface{}{id}, args...)...)
+		return err
+	})
+	return t
+}
+
+// Delete registers a delete of the row for the event type T
+func Delete[T any](t *Table) *Table {
+	register(t, func(event eventsourcing.Event, data T) Values { return nil }, func(tx *sql.Tx, id string, v Values) error {
+		statement := fmt.Sprintf("DELETE FROM %s WHERE %s = %s", t.dialect.quote(t.name), t.dialect.quote("id"), t.dialect.placeholder(1))
+		_, err := tx.Exec(statement, id)
+		return err
+	})
+	return t
+}
+
+// register binds the operation to the event type T
+func register[T any](t *Table, values func(event eventsourcing.Event, data T) Values, exec func(tx *sql.Tx, id string, v Values) error) {
+	typ, reason := internal.EventType[T]()
+	t.operations[typ] = func(tx *sql.Tx, event eventsourcing.Event) error {
+		data, ok := internal.EventData[T](event.Data())
+		if !ok {
+			return nil
+		}
+		return exec(tx, event.AggregateID(), values(event, data))
+	}
+	if !slices.Contains(t.reasons, reason) {
+		t.reasons = append(t.reasons, reason)
+	}
+}
+
+// columns returns the quoted column names, the placeholders starting from the index and the arguments of the
+// values sorted on the column name. Expressions are put in place of the placeholder.
+func (t *Table) columns(v Values, index int) ([]string, []string, []interface{}) {
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	columns := make([]string, len(names))
+	placeholders := make([]string, len(names))
+	var args []interface{}
+	for i, name := range names {
+		columns[i] = t.dialect.quote(name)
+		if expr, ok := v[name].(Expr); ok {
+			placeholders[i] = string(expr)
+			continue
+		}
+		placeholders[i] = t.dialect.placeholder(index)
+		args = append(args, v[name])
+		index++
+	}
+	return columns, placeholders, args
+}
+
+// handle applies the operation registered on the event type in the transaction
+func (t *Table) handle(tx *sql.Tx, event eventsourcing.Event) error {
+	op, ok := t.operations[reflect.TypeOf(event.Data())]
+	if !ok {
+		return nil
+	}
+	return op(tx, event)
+}
+
+// Projection creates a transactional projection that keeps the table up to date. Events without an operation
+// are skipped on their reason before they are deserialized.
+func (t *Table) Projection(fetchFrom core.FetcherFrom) *eventsourcing.Projection {
+	return eventsourcing.NewTxProjection(t.name, t.transactor(t.handle), func(start core.Version) core.Fetcher {
+		return fetcher.Reason(fetchFrom(start), t.reasons...)
+	})
+}
+
+// newTable creates the table in the database with the statement
+func newTable(db *sql.DB, name string, d dialect, statement string, transactor func(f func(tx *sql.Tx, event eventsourcing.Event) error) eventsourcing.Transactor) (*Table, error) {
+	_, err := db.Exec(statement)
+	if err != nil {
+		return nil, err
+	}
+	return &Table{
+		name:       name,
+		dialect:    d,
+		transactor: transactor,
+		operations: make(map[reflect.Type]operation),
+	}, nil
+}
+
+// definition returns the table name and column definitions used in the create table statement
+func definition(d dialect, name, idType string, columns []Column) string {
+	definitions := []string{fmt.Sprintf("%s %s NOT NULL PRIMARY KEY", d.quote("id"), idType)}
+	for _, column := range columns {
+		definitions = append(definitions, fmt.Sprintf("%s %s", d.quote(column.Name), column.Type))
+	}
+	return fmt.Sprintf("%s (%s)", d.quote(name), strings.Join(definitions, ", "))
+}
+
+// quoteDouble quotes the identifier with double quotes
+func quoteDouble(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// upsertOnConflict is the upsert statement in SQLite and Postgres
+func upsertOnConflict(t *Table, columns []string, placeholders []string) string {
+	id := t.dialect.quote("id")
+	statement := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) ",
+		t.dialect.quote(t.name), strings.Join(append([]string{id}, columns...), ", "), strings.Join(append([]string{t.dialect.placeholder(1)}, placeholders...), ", "), id)
+	if len(columns) == 0 {
+		return statement + "DO NOTHING"
+	}
+	set := make([]string, len(columns))
+	for i, column := range columns {
+		set[i] = column + " = excluded." + column
+	}
+	return statement + "DO UPDATE SET " + strings.Join(set, ", ")
+}
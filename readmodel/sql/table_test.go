@@ -0,0 +1,160 @@
+package sql_test
+
+import (
+	"context"
+	gosql "database/sql"
+	"testing"
+
+	"github.com/r23vme/eventsourcing"
+	"github.com/r23vme/eventsourcing/aggregate"
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/eventstore/memory"
+	"github.com/r23vme/eventsourcing/readmodel/sql"
+)
+
+type Account struct {
+	aggregate.Root
+	Owner   string
+	Balance int
+}
+
+type Opened struct {
+	Owner string
+}
+
+type Deposited struct {
+	Balance int
+}
+
+type Renamed struct {
+	Owner string
+}
+
+type Noted struct{}
+
+type Closed struct{}
+
+func (a *Account) Transition(event eventsourcing.Event) {
+	switch e := event.Data().(type) {
+	case *Opened:
+		a.Owner = e.Owner
+	case *Deposited:
+		a.Balance = e.Balance
+	case *Renamed:
+		a.Owner = e.Owner
+	}
+}
+
+func (a *Account) Register(f aggregate.RegisterFunc) {
+	f(&Opened{}, &Deposited{}, &Renamed{}, &Noted{}, &Closed{})
+}
+
+type row struct {
+	owner    string
+	balance  int
+	deposits int
+}
+
+// testTable runs a table projection on the database
+func testTable(t *testing.T, db *gosql.DB, newTable func(db *gosql.DB, name string, columns ...sql.Column) (*sql.Table, error), newCheckpointStore func(db *gosql.DB) (core.CheckpointStore, error)) {
+	es := memory.Create()
+	aggregate.Register(&Account{})
+
+	table, err := newTable(db, "accounts",
+		sql.Column{Name: "owner", Type: "VARCHAR(255)"},
+		sql.Column{Name: "balance", Type: "INTEGER"},
+		sql.Column{Name: "deposits", Type: "INTEGER"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the table is only created once
+	_, err = newTable(db, "accounts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sql.Insert(table, func(event eventsourcing.Event, data *Opened) sql.Values {
+		return sql.Values{"owner": data.Owner, "balance": 0, "deposits": 0}
+	})
+	sql.Update(table, func(event eventsourcing.Event, data *Deposited) sql.Values {
+		return sql.Values{"balance": data.Balance, "deposits": sql.Expr("deposits + 1")}
+	})
+	sql.Upsert(table, func(event eventsourcing.Event, data Renamed) sql.Values {
+		return sql.Values{"owner": data.Owner}
+	})
+	sql.Delete[*Closed](table)
+
+	accounts := make(map[string]*Account)
+	for _, owner := range []string{"kalle", "anka", "musse"} {
+		account := &Account{}
+		aggregate.TrackChange(account, &Opened{Owner: owner})
+		aggregate.TrackChange(account, &Deposited{Balance: 10})
+		aggregate.TrackChange(account, &Noted{})
+		aggregate.TrackChange(account, &Deposited{Balance: 25})
+		err = aggregate.Save(es, account)
+		if err != nil {
+			t.Fatal(err)
+		}
+		accounts[owner] = account
+	}
+	aggregate.TrackChange(accounts["anka"], &Renamed{Owner: "kajsa"})
+	aggregate.TrackChange(accounts["musse"], &Closed{})
+	err = aggregate.Save(es, accounts["anka"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.Save(es, accounts["musse"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := table.Projection(func(start core.Version) core.Fetcher {
+		return es.All(start, 5)
+	})
+	result := p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	rows, err := db.Query(`SELECT id, owner, balance, deposits FROM accounts`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	got := make(map[string]row)
+	for rows.Next() {
+		var id string
+		var r row
+		err = rows.Scan(&id, &r.owner, &r.balance, &r.deposits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[id] = r
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 rows got %v", got)
+	}
+	expected := map[string]row{
+		accounts["kalle"].ID(): {owner: "kalle", balance: 25, deposits: 2},
+		accounts["anka"].ID():  {owner: "kajsa", balance: 25, deposits: 2},
+	}
+	for id, r := range expected {
+		if got[id] != r {
+			t.Fatalf("expected row %v got %v", r, got[id])
+		}
+	}
+
+	// the checkpoint is stored on the table name
+	cs, err := newCheckpointStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkpoint, err := cs.Load(context.Background(), "accounts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint != 14 {
+		t.Fatalf("expected checkpoint 14 got %d", checkpoint)
+	}
+}
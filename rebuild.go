@@ -0,0 +1,98 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+type buildFunc func(target string) *Projection
+
+// VersionedProjection builds a read model for a version of the projection code. When the version differs
+// from the active version the read model is rebuilt from the start of the event stream into a new target,
+// while the read model of the active version keeps serving. When the new read model has caught up with the
+// event stream the Swap hook is called and the version becomes the active version.
+type VersionedProjection struct {
+	name       string
+	version    uint64
+	versions   core.CheckpointStore
+	projection *Projection
+	// Swap is called when the read model in target has caught up and should replace the active read model
+	Swap func(ctx context.Context, target string) error
+}
+
+// NewVersionedProjection creates a versioned projection where the build function creates the projection
+// writing the read model to target. The active version is stored on "<name>/version" in the checkpoint store,
+// apart from the checkpoints of the projections.
+func NewVersionedProjection(name string, version uint64, versions core.CheckpointStore, build buildFunc) *VersionedProjection {
+	v := VersionedProjection{
+		name:     name,
+		version:  version,
+		versions: versions,
+	}
+	v.projection = build(v.Target(version))
+	return &v
+}
+
+// Target returns the name of the read model built by a version of the projection
+func (v *VersionedProjection) Target(version uint64) string {
+	return fmt.Sprintf("%s_v%d", v.name, version)
+}
+
+// key returns the name the active version is stored on in the checkpoint store
+func (v *VersionedProjection) key() string {
+	return v.name + "/version"
+}
+
+// Projection returns the projection building the read model of the version
+func (v *VersionedProjection) Projection() *Projection {
+	return v.projection
+}
+
+// ActiveVersion returns the version of the read model that is serving, 0 if no version has been swapped in
+func (v *VersionedProjection) ActiveVersion(ctx context.Context) (uint64, error) {
+	version, err := v.versions.Load(ctx, v.key())
+	if errors.Is(err, core.ErrCheckpointNotFound) {
+		return 0, nil
+	}
+	return uint64(version), err
+}
+
+// Rebuild runs the projection to the end of the event stream and swaps in its read model if the version is
+// not the active version. It returns without running the projection if the version is already active.
+func (v *VersionedProjection) Rebuild(ctx context.Context) error {
+	active, err := v.ActiveVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if active == v.version {
+		return nil
+	}
+	result := v.projection.RunToEnd(ctx)
+	if result.Error != nil {
+		return result.Error
+	}
+	if v.projection.DryRun {
+		return nil
+	}
+	if v.Swap != nil {
+		err = v.Swap(ctx, v.Target(v.version))
+		if err != nil {
+			return err
+		}
+	}
+	return v.versions.Save(v.key(), core.Version(v.version))
+}
+
+// Run rebuilds the read model if the version is not active and continues to run the projection until
+// the context is cancelled.
+func (v *VersionedProjection) Run(ctx context.Context, pace time.Duration) error {
+	err := v.Rebuild(ctx)
+	if err != nil {
+		return err
+	}
+	return v.projection.Run(ctx, pace)
+}
@@ -0,0 +1,284 @@
+package eventsourcing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+// subscription is the buffer of events read by the shared reader for a projection
+type subscription struct {
+	events   chan core.Event
+	attached bool // the projection has handled the events read before it was attached
+}
+
+// sharedReader reads the event stream once for the projections in a group. A projection that has caught up
+// with the reader is attached and receives the events in its buffer. A projection that is behind, or that
+// falls behind when its buffer is full, fetches the events from the event store until it has caught up again.
+type sharedReader struct {
+	fetchFrom     core.FetcherFrom
+	size          int
+	wake          chan struct{}
+	read          sync.Mutex // one read at a time
+	lock          sync.Mutex
+	subscriptions map[*Projection]*subscription
+	next          core.Version // global version to read from
+	last          core.Version // global version of the last event read
+}
+
+func newSharedReader(fetchFrom core.FetcherFrom, size int) *sharedReader {
+	if size <= 0 {
+		size = 1000
+	}
+	return &sharedReader{
+		fetchFrom:     fetchFrom,
+		size:          size,
+		wake:          make(chan struct{}, 1),
+		subscriptions: make(map[*Projection]*subscription),
+	}
+}
+
+// run reads the event stream on each pace or when woken until the context is done
+func (r *sharedReader) run(ctx context.Context, pace time.Duration) {
+	for {
+		r.readToEnd(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake:
+		case <-time.After(pace):
+		}
+	}
+}
+
+// trigger wakes the reader without waiting for it to read
+func (r *sharedReader) trigger() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// readToEnd reads the events after the last read event and puts them in the buffers of the attached projections.
+// The projections that received events are triggered to run.
+func (r *sharedReader) readToEnd(ctx context.Context) {
+	r.read.Lock()
+	defer r.read.Unlock()
+
+	received := make(map[*Projection]struct{})
+	defer func() {
+		for p := range received {
+			p.TriggerAsync()
+		}
+	}()
+	for {
+		r.lock.Lock()
+		next, attached := r.next, r.attached()
+		r.lock.Unlock()
+		if !attached || ctx.Err() != nil {
+			return
+		}
+
+		iterator, err := r.fetchFrom(next)()
+		if err != nil {
+			// the attached projections fetch the events from the event store if the reader does not catch up
+			return
+		}
+		var ran bool
+		for iterator.Next() {
+			event, err := iterator.Value()
+			if err != nil {
+				iterator.Close()
+				return
+			}
+			ran = true
+			if !r.publish(event, received) {
+				break
+			}
+		}
+		iterator.Close()
+		if !ran {
+			return
+		}
+	}
+}
+
+// publish puts the event in the buffers of the attached projections, a projection with a full buffer is detached.
+// It returns false if there are no attached projections left.
+func (r *sharedReader) publish(event core.Event, received map[*Projection]struct{}) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	// the reader could have moved to a later position while no projection was attached
+	if event.GlobalVersion < r.next {
+		return r.attached()
+	}
+	for p, s := range r.subscriptions {
+		if !s.attached {
+			continue
+		}
+		select {
+		case s.events <- event:
+			received[p] = struct{}{}
+		default:
+			s.attached = false
+		}
+	}
+	r.last = event.GlobalVersion
+	r.next = event.GlobalVersion + 1
+	return r.attached()
+}
+
+func (r *sharedReader) attached() bool {
+	for _, s := range r.subscriptions {
+		if s.attached {
+			return true
+		}
+	}
+	return false
+}
+
+// take returns the events in the buffer of the projection starting from the global version. It returns false
+// if the projection is not attached and has to fetch the events from the event store.
+func (r *sharedReader) take(p *Projection, start core.Version) ([]core.Event, bool) {
+	r.lock.Lock()
+	s, ok := r.subscriptions[p]
+	if !ok {
+		s = &subscription{events: make(chan core.Event, r.size)}
+		r.subscriptions[p] = s
+	}
+	if !s.attached {
+		// the projection has not handled all events read by the reader
+		if start <= r.last {
+			r.lock.Unlock()
+			return nil, false
+		}
+		// the events in the buffer are from before the projection was detached
+		for len(s.events) > 0 {
+			<-s.events
+		}
+		if !r.attached() {
+			// no other projection is attached, continue to read from the position of the projection
+			r.next = start
+			r.last = start - 1
+			r.trigger()
+		}
+		s.attached = true
+	}
+	events := s.events
+	r.lock.Unlock()
+
+	var taken []core.Event
+	for {
+		select {
+		case event := <-events:
+			// the reader can have read events the projection fetched from the event store before it was attached
+			if event.GlobalVersion >= start {
+				taken = append(taken, event)
+			}
+		default:
+			return taken, true
+		}
+	}
+}
+
+// unsubscribe removes the buffer of the projection
+func (r *sharedReader) unsubscribe(p *Projection) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.subscriptions, p)
+}
+
+// fetcherFrom returns a fetcher that takes the events from the buffer of the projection when it's attached
+// and fetches them from the event store with the projections own fetcher when it's not.
+//
+// The first fetch is made with the projections own fetcher. If it skips or transforms events, as the fetcher
+// combinators do, the projection keeps its own fetcher and never reads the events of the shared reader.
+func (r *sharedReader) fetcherFrom(p *Projection, start core.Version) core.Fetcher {
+	var own core.Fetcher
+	var probed, exclusive bool
+	return func() (core.Iterator, error) {
+		if probed && !exclusive {
+			events, ok := r.take(p, start)
+			if ok {
+				own = nil
+				if len(events) > 0 {
+					start = events[len(events)-1].GlobalVersion + 1
+				}
+				return &sliceIterator{events: events}, nil
+			}
+		}
+		if own == nil {
+			own = p.fetchFrom(start)
+		}
+		iterator, err := own()
+		if err != nil {
+			return nil, err
+		}
+		if !probed {
+			probed = true
+			_, exclusive = iterator.(core.PositionIterator)
+		}
+		if exclusive {
+			return iterator, nil
+		}
+		return &trackingIterator{iterator: iterator, next: &start}, nil
+	}
+}
+
+// from returns a fetcher starting from the global version, from the shared reader if the projection is in a
+// group with a shared reader
+func (p *Projection) from(start core.Version) core.Fetcher {
+	if r := p.shared.Load(); r != nil {
+		return r.fetcherFrom(p, start)
+	}
+	return p.fetchFrom(start)
+}
+
+// sliceIterator iterates the events taken from the buffer
+type sliceIterator struct {
+	events []core.Event
+	index  int
+}
+
+func (i *sliceIterator) Next() bool {
+	if i.index >= len(i.events) {
+		return false
+	}
+	i.index++
+	return true
+}
+
+func (i *sliceIterator) Value() (core.Event, error) {
+	return i.events[i.index-1], nil
+}
+
+func (i *sliceIterator) Close() {}
+
+// trackingIterator keeps the global version after the last fetched event
+type trackingIterator struct {
+	iterator core.Iterator
+	next     *core.Version
+}
+
+func (i *trackingIterator) Next() bool {
+	return i.iterator.Next()
+}
+
+func (i *trackingIterator) Value() (core.Event, error) {
+	event, err := i.iterator.Value()
+	if err == nil {
+		*i.next = event.GlobalVersion + 1
+	}
+	return event, err
+}
+
+func (i *trackingIterator) Close() {
+	i.iterator.Close()
+}
+
+func (i *trackingIterator) Position() core.Version {
+	return core.IteratorPosition(i.iterator, 0)
+}
@@ -0,0 +1,144 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+	"github.com/r23vme/eventsourcing/internal"
+)
+
+// stateSnapshotType is the snapshot type the state of a state projection is stored on
+const stateSnapshotType = "projection"
+
+// StateProjection builds an in memory state from the events. The state is stored together with the global
+// version of the last handled event in a snapshot store, and restored when the projection starts so only the
+// events after the stored state are handled.
+//
+// The state is stored when the projection stores its checkpoint, the projection properties CheckpointEvery and
+// CheckpointInterval controls how often. Default the state is stored at most every 10 seconds while there are
+// events to handle and when the projection stops.
+type StateProjection[T any] struct {
+	snapshots  core.SnapshotStore
+	projection *Projection
+	lock       sync.RWMutex
+	state      T
+	applied    core.Version // global version of the last event applied to the state
+}
+
+// NewStateProjection creates a projection where apply updates the state with each event. The state is stored
+// on the name in the snapshot store and serialized with the snapshot encoder.
+//
+// Each event is applied on a copy of the state that replaces the state when apply succeeds, an event that fails
+// is not partly applied when it's retried. The copy is made with the Clone method of the state if it has a
+// Clone() T method, or else by serializing the state with the snapshot encoder.
+func NewStateProjection[T any](name string, ss core.SnapshotStore, fetchFrom core.FetcherFrom, apply func(ctx context.Context, event Event, state *T) error) *StateProjection[T] {
+	s := StateProjection[T]{
+		snapshots: ss,
+	}
+	s.projection = NewCheckpointProjectionContext(name, stateStore[T]{&s}, fetchFrom, func(ctx context.Context, event Event) error {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		state, err := s.clone()
+		if err != nil {
+			return err
+		}
+		err = apply(ctx, event, &state)
+		if err != nil {
+			return err
+		}
+		s.state = state
+		s.applied = core.Version(event.GlobalVersion())
+		return nil
+	})
+	// the state is stored with the checkpoint, which only covers the applied events if they are applied in order
+	s.projection.sequential = true
+	s.projection.CheckpointInterval = time.Second * 10
+	return &s
+}
+
+// clone returns a copy of the state
+func (s *StateProjection[T]) clone() (T, error) {
+	if c, ok := any(&s.state).(interface{ Clone() T }); ok {
+		return c.Clone(), nil
+	}
+	var state T
+	data, err := internal.SnapshotEncoder.Serialize(s.state)
+	if err != nil {
+		return state, err
+	}
+	err = internal.SnapshotEncoder.Deserialize(data, &state)
+	return state, err
+}
+
+// Projection returns the projection building the state, used to run it or set its properties
+func (s *StateProjection[T]) Projection() *Projection {
+	return s.projection
+}
+
+// Run restores the state and runs the projection until the context is cancelled
+func (s *StateProjection[T]) Run(ctx context.Context, pace time.Duration) error {
+	return s.projection.Run(ctx, pace)
+}
+
+// Read calls f with the state, the state must not be changed or kept after f returns
+func (s *StateProjection[T]) Read(f func(state *T)) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	f(&s.state)
+}
+
+// stateStore is the checkpoint store of a state projection, it stores and restores the state with the checkpoint
+type stateStore[T any] struct {
+	s *StateProjection[T]
+}
+
+// Load restores the state from the snapshot store and returns its global version
+func (ss stateStore[T]) Load(ctx context.Context, name string) (core.Version, error) {
+	s := ss.s
+	snapshot, err := s.snapshots.Get(ctx, name, stateSnapshotType)
+	if errors.Is(err, core.ErrSnapshotNotFound) {
+		return 0, core.ErrCheckpointNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	var state T
+	err = internal.SnapshotEncoder.Deserialize(snapshot.State, &state)
+	if err != nil {
+		return 0, err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.state = state
+	s.applied = snapshot.GlobalVersion
+	return snapshot.GlobalVersion, nil
+}
+
+// Save stores the state with the global version. The state can't be moved back to before the last applied
+// event except to the start of the event stream where the state is cleared.
+func (ss stateStore[T]) Save(name string, version core.Version) error {
+	s := ss.s
+	s.lock.Lock()
+	if version == 0 {
+		var state T
+		s.state = state
+		s.applied = 0
+	} else if version < s.applied {
+		s.lock.Unlock()
+		return ErrNotSeekable
+	}
+	data, err := internal.SnapshotEncoder.Serialize(s.state)
+	s.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	return s.snapshots.Save(core.Snapshot{
+		ID:            name,
+		Type:          stateSnapshotType,
+		GlobalVersion: version,
+		State:         data,
+	})
+}
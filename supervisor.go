@@ -0,0 +1,179 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/r23vme/eventsourcing/internal"
+)
+
+// RestartMode decides if a projection in a group is restarted when it returns an error
+type RestartMode int
+
+const (
+	// RestartNever leaves the projection failed and sends the error on the group error channel
+	RestartNever RestartMode = iota
+	// RestartAlways restarts the projection immediately
+	RestartAlways
+	// RestartBackoff restarts the projection after a delay that is doubled on each restart
+	RestartBackoff
+)
+
+// RestartPolicy is applied by a group when a projection returns an error
+type RestartPolicy struct {
+	Mode RestartMode
+	// MaxAttempts is the max number of restarts in a row without handling any new events, 0 has no limit
+	MaxAttempts int
+	// Backoff is the delay before the first restart in RestartBackoff mode
+	Backoff time.Duration
+	// MaxBackoff limits the delay between restarts, 0 has no limit
+	MaxBackoff time.Duration
+}
+
+// delay returns the time to wait before the restart attempt and false if the projection should not be restarted
+func (r RestartPolicy) delay(attempt int) (time.Duration, bool) {
+	if r.Mode == RestartNever || (r.MaxAttempts > 0 && attempt >= r.MaxAttempts) {
+		return 0, false
+	}
+	if r.Mode == RestartAlways {
+		return 0, true
+	}
+	return internal.Backoff(r.Backoff, r.MaxBackoff, attempt), true
+}
+
+// ProjectionState is the state of a projection in a group
+type ProjectionState string
+
+const (
+	ProjectionRunning    ProjectionState = "running"
+	ProjectionRestarting ProjectionState = "restarting"
+	ProjectionFailed     ProjectionState = "failed"
+	ProjectionStopped    ProjectionState = "stopped"
+	// ProjectionStandby is a running projection waiting for the lease held by another process
+	ProjectionStandby ProjectionState = "standby"
+	// ProjectionPaused is a running projection that is paused
+	ProjectionPaused ProjectionState = "paused"
+)
+
+// ProjectionStatus is a snapshot of the state of a projection in a group
+type ProjectionStatus struct {
+	Name                     string
+	State                    ProjectionState
+	Restarts                 int
+	LastError                error
+	LastHandledGlobalVersion Version
+}
+
+// member is a projection supervised by a group
+type member struct {
+	projection *Projection
+	cancel     context.CancelFunc
+	stop       chan struct{}
+	stopOnce   sync.Once
+	done       chan struct{}
+
+	lock      sync.Mutex
+	state     ProjectionState
+	restarts  int
+	lastError error
+}
+
+// drain asks the projection to stop after the fetched events are handled
+func (m *member) drain() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+}
+
+// unshare detaches the projection from the shared reader in the group, the projection fetch the events after
+// the checkpoint the next time it runs
+func (m *member) unshare() {
+	r := m.projection.shared.Swap(nil)
+	if r == nil {
+		return
+	}
+	r.unsubscribe(m.projection)
+	m.projection.exec.Lock()
+	m.projection.fetchF = nil
+	m.projection.exec.Unlock()
+}
+
+func (m *member) set(state ProjectionState, restarts int, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.state = state
+	m.restarts = restarts
+	if err != nil {
+		m.lastError = err
+	}
+}
+
+func (m *member) status() ProjectionStatus {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	state := m.state
+	if state == ProjectionRunning && m.projection.Lease != nil && !m.projection.Lease.Leader() {
+		state = ProjectionStandby
+	} else if state == ProjectionRunning && m.projection.Paused() {
+		state = ProjectionPaused
+	}
+	return ProjectionStatus{
+		Name:                     m.projection.Name,
+		State:                    state,
+		Restarts:                 m.restarts,
+		LastError:                m.lastError,
+		LastHandledGlobalVersion: Version(m.projection.position.Load()),
+	}
+}
+
+// supervise runs the projection and restarts it according to its restart policy
+func (g *ProjectionGroup) supervise(ctx context.Context, m *member, errChan chan error) {
+	defer g.wg.Done()
+	defer close(m.done)
+	defer m.cancel()
+	defer m.unshare()
+	defer m.projection.graph.Store(nil)
+
+	restarts, attempts := 0, 0
+	var failedAt uint64
+	for {
+		m.set(ProjectionRunning, restarts, nil)
+		err := m.projection.run(ctx, g.Pace, m.stop)
+		if err == nil || errors.Is(err, context.Canceled) {
+			m.set(ProjectionStopped, restarts, nil)
+			return
+		}
+
+		// the restart attempts are counted from the last time the projection made progress
+		position := m.projection.position.Load()
+		if position > failedAt {
+			attempts = 0
+		}
+		failedAt = position
+
+		delay, ok := m.projection.Restart.delay(attempts)
+		if !ok {
+			m.set(ProjectionFailed, restarts, err)
+			select {
+			case errChan <- err:
+			case <-ctx.Done():
+			case <-m.stop:
+			}
+			return
+		}
+		attempts++
+		restarts++
+		m.set(ProjectionRestarting, restarts, err)
+		select {
+		case <-ctx.Done():
+			m.set(ProjectionStopped, restarts, nil)
+			return
+		case <-m.stop:
+			m.set(ProjectionStopped, restarts, nil)
+			return
+		case <-time.After(delay):
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package eventsourcing
+
+import (
+	"context"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+// Transaction is used by a transactional projection to handle events and commit the checkpoint
+// of the last handled event in one atomic operation.
+type Transaction interface {
+	// Handle is called for each event in the transaction
+	Handle(event Event) error
+	// Commit stores the checkpoint on the projection name and commits the transaction
+	Commit(name string, checkpoint core.Version) error
+	// Rollback discards the events handled in the transaction
+	Rollback() error
+}
+
+// Transactor begins the transactions used by a transactional projection. It's also the checkpoint
+// store the projection resumes from.
+type Transactor interface {
+	core.CheckpointStore
+	Begin(ctx context.Context) (Transaction, error)
+}
+
+// NewTxProjection creates a projection that handles events in transactions begun by the transactor.
+// The checkpoint is committed in the same transaction as the events, which means that an event is
+// never handled twice even if the process crash in the middle of a transaction.
+//
+// The projection properties CheckpointEvery and CheckpointInterval controls how many events that are
+// handled in each transaction.
+func NewTxProjection(name string, t Transactor, fetchFrom core.FetcherFrom) *Projection {
+	projection := NewCheckpointProjection(name, t, fetchFrom, nil)
+	projection.transactor = t
+	projection.callbackF = projection.handleTx
+	return projection
+}
+
+// begin starts a transaction if there is no open transaction
+func (p *Projection) begin(ctx context.Context) error {
+	if p.tx != nil {
+		return nil
+	}
+	tx, err := p.transactor.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	p.tx = tx
+	return nil
+}
+
+// handleTx handles the event in the open transaction
+func (p *Projection) handleTx(ctx context.Context, event Event) error {
+	return p.tx.Handle(event)
+}
+
+// commit commits the open transaction together with the checkpoint
+func (p *Projection) commit() error {
+	if p.tx == nil {
+		return nil
+	}
+	err := p.tx.Commit(p.Name, p.pending)
+	p.tx = nil
+	if err != nil {
+		return err
+	}
+	p.unsaved = 0
+	p.savedAt = time.Now()
+	p.committed = p.lastHandled
+	p.setPosition(p.committed)
+	// the iteration could have stopped in the middle of the fetched events, continue after the commit
+	p.fetchF = p.from(p.pending + 1)
+	return nil
+}
+
+// rollback discards the open transaction, the next run starts from the committed checkpoint
+func (p *Projection) rollback() {
+	if p.tx != nil {
+		p.tx.Rollback()
+		p.tx = nil
+	}
+	p.unsaved = 0
+	p.scanned = 0
+	p.fetchF = nil
+}
+
+// discard drops the handled events in a dry run, the projection continues after the last handled event
+func (p *Projection) discard() {
+	if p.tx != nil {
+		p.tx.Rollback()
+		p.tx = nil
+		p.committed = p.lastHandled
+		p.setPosition(p.committed)
+		p.fetchF = p.from(p.pending + 1)
+	}
+	p.unsaved = 0
+	p.savedAt = time.Now()
+}
@@ -0,0 +1,151 @@
+package eventsourcing
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/r23vme/eventsourcing/core"
+)
+
+// Window is a time window of events and the state aggregated from them
+type Window[T any] struct {
+	Start time.Time
+	End   time.Time
+	State T
+}
+
+// windows is the persisted state of a window projection
+type windows[T any] struct {
+	// Watermark is the latest event timestamp handled
+	Watermark time.Time
+	// Open is the state of the open windows on their start in unix nano
+	Open map[int64]*T
+}
+
+// WindowProjection groups the events into windows on their timestamp. Each event is added to the windows it
+// belongs to and a window is passed to the closed handler when an event with a timestamp after the end of the
+// window, plus the allowed lateness, is handled.
+//
+// The open windows are stored in a snapshot store together with the global version of the last handled event, as
+// in a state projection, so a restarted projection continues with its open windows. A window can be passed to the
+// closed handler again if the projection restarts before the state is stored, or if the handler fails and the
+// event is retried, the handler should be idempotent on the window start.
+type WindowProjection[T any] struct {
+	state *StateProjection[windows[T]]
+	// Size is the length of the windows
+	Size time.Duration
+	// Slide is how often a window starts, default Size where the windows are tumbling and each event belongs to one
+	// window. A Slide shorter than Size makes the windows overlap.
+	Slide time.Duration
+	// Lateness is how long after the end of a window events are added to it before it's closed. Events for a
+	// closed window are dropped.
+	Lateness time.Duration
+}
+
+// NewWindowProjection creates a projection where add aggregates the events into the state of the windows of
+// the size and closed is called with each closed window. The windows are aligned on the zero time, a window of a
+// day starts at midnight UTC.
+func NewWindowProjection[T any](name string, ss core.SnapshotStore, fetchFrom core.FetcherFrom, size time.Duration, add func(ctx context.Context, event Event, state *T) error, closed func(ctx context.Context, window Window[T]) error) *WindowProjection[T] {
+	w := WindowProjection[T]{
+		Size: size,
+	}
+	w.state = NewStateProjection(name, ss, fetchFrom, func(ctx context.Context, event Event, state *windows[T]) error {
+		if state.Open == nil {
+			state.Open = make(map[int64]*T)
+		}
+		timestamp := event.Timestamp()
+		if timestamp.After(state.Watermark) {
+			state.Watermark = timestamp
+		}
+		// the windows are closed before the event is added, the state is rolled back if the closed handler fails
+		err := w.close(ctx, state, closed)
+		if err != nil {
+			return err
+		}
+		for _, start := range w.starts(timestamp) {
+			// the window is closed
+			if !start.Add(w.Size + w.Lateness).After(state.Watermark) {
+				continue
+			}
+			s, ok := state.Open[start.UnixNano()]
+			if !ok {
+				s = new(T)
+				state.Open[start.UnixNano()] = s
+			}
+			err := add(ctx, event, s)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return &w
+}
+
+// starts returns the start of the windows the timestamp belongs to
+func (w *WindowProjection[T]) starts(timestamp time.Time) []time.Time {
+	slide := w.Slide
+	if slide <= 0 {
+		slide = w.Size
+	}
+	var starts []time.Time
+	for start := timestamp.Truncate(slide); start.Add(w.Size).After(timestamp); start = start.Add(-slide) {
+		starts = append(starts, start)
+	}
+	return starts
+}
+
+// close passes the windows ending before the watermark, minus the lateness, to the closed handler in start order
+func (w *WindowProjection[T]) close(ctx context.Context, state *windows[T], closed func(ctx context.Context, window Window[T]) error) error {
+	var starts []int64
+	for start := range state.Open {
+		starts = append(starts, start)
+	}
+	slices.Sort(starts)
+	for _, start := range starts {
+		window := Window[T]{
+			Start: time.Unix(0, start).UTC(),
+			State: *state.Open[start],
+		}
+		window.End = window.Start.Add(w.Size)
+		if window.End.Add(w.Lateness).After(state.Watermark) {
+			break
+		}
+		err := closed(ctx, window)
+		if err != nil {
+			return err
+		}
+		delete(state.Open, start)
+	}
+	return nil
+}
+
+// Projection returns the projection building the windows, used to run it or set its properties
+func (w *WindowProjection[T]) Projection() *Projection {
+	return w.state.Projection()
+}
+
+// Run restores the open windows and runs the projection until the context is cancelled
+func (w *WindowProjection[T]) Run(ctx context.Context, pace time.Duration) error {
+	return w.state.Run(ctx, pace)
+}
+
+// Open returns the open windows in start order, the state of the windows must not be changed
+func (w *WindowProjection[T]) Open() []Window[T] {
+	var open []Window[T]
+	w.state.Read(func(state *windows[T]) {
+		for start, s := range state.Open {
+			window := Window[T]{
+				Start: time.Unix(0, start).UTC(),
+				State: *s,
+			}
+			window.End = window.Start.Add(w.Size)
+			open = append(open, window)
+		}
+	})
+	slices.SortFunc(open, func(a, b Window[T]) int {
+		return a.Start.Compare(b.Start)
+	})
+	return open
+}